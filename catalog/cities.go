@@ -0,0 +1,82 @@
+package catalog
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//go:embed cities.json
+var citiesData []byte
+
+// City — город вылета в России и СНГ с IATA-кодом города.
+type City struct {
+	Name    string   `json:"name"`
+	IATA    string   `json:"iata"`
+	Country string   `json:"country"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// String возвращает название города с кодом, например «Москва (MOW)».
+func (c City) String() string {
+	return fmt.Sprintf("%s (%s)", c.Name, c.IATA)
+}
+
+func (c City) names() []string {
+	return append([]string{c.Name, c.IATA}, c.Aliases...)
+}
+
+// maxCitySuggestions — сколько вариантов города предлагается кнопками.
+const maxCitySuggestions = 4
+
+var cities = mustLoadCities()
+
+func mustLoadCities() []City {
+	var data []City
+	if err := json.Unmarshal(citiesData, &data); err != nil {
+		panic(fmt.Sprintf("некорректный каталог городов: %v", err))
+	}
+	return data
+}
+
+// FindCities ищет город вылета по названию, синониму или коду. При точном
+// совпадении возвращает один город и exact = true. Иначе возвращает города,
+// название которых начинается с запроса или похоже на него, — от наиболее похожих.
+func FindCities(name string) (found []City, exact bool) {
+	query := Normalize(name)
+	if query == "" {
+		return nil, false
+	}
+
+	type candidate struct {
+		city     City
+		distance int
+	}
+	var candidates []candidate
+	for _, city := range cities {
+		distance := matchNames(query, city.names())
+		if distance == 0 {
+			return []City{city}, true
+		}
+
+		switch {
+		case len([]rune(query)) >= 3 && strings.HasPrefix(Normalize(city.Name), query):
+			candidates = append(candidates, candidate{city, 0})
+		case distance >= 0 && distance <= maxTypos(query):
+			candidates = append(candidates, candidate{city, distance})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+	for i, c := range candidates {
+		if i == maxCitySuggestions {
+			break
+		}
+		found = append(found, c.city)
+	}
+	return found, false
+}
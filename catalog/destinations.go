@@ -0,0 +1,64 @@
+package catalog
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+//go:embed destinations.json
+var destinationsData []byte
+
+// Destination — страна или популярный курорт. У курорта указана страна.
+type Destination struct {
+	Name    string   `json:"name"`
+	Country string   `json:"country,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// String возвращает каноническое название направления: курорт — вместе со страной.
+func (d Destination) String() string {
+	if d.Country == "" {
+		return d.Name
+	}
+	return fmt.Sprintf("%s (%s)", d.Name, d.Country)
+}
+
+func (d Destination) names() []string {
+	return append([]string{d.Name}, d.Aliases...)
+}
+
+var destinations = mustLoadDestinations()
+
+func mustLoadDestinations() []Destination {
+	var data struct {
+		Countries []Destination `json:"countries"`
+		Resorts   []Destination `json:"resorts"`
+	}
+	if err := json.Unmarshal(destinationsData, &data); err != nil {
+		panic(fmt.Sprintf("некорректный каталог направлений: %v", err))
+	}
+	return append(data.Countries, data.Resorts...)
+}
+
+// FindDestination ищет направление в каталоге по названию или синониму.
+// exact = false означает, что найдено похожее название — вероятно, в ответе
+// опечатка. ok = false, если подходящего направления в каталоге нет.
+func FindDestination(name string) (destination Destination, exact, ok bool) {
+	query := Normalize(name)
+	if query == "" {
+		return Destination{}, false, false
+	}
+
+	bestDistance := maxTypos(query) + 1
+	for _, candidate := range destinations {
+		distance := matchNames(query, candidate.names())
+		if distance == 0 {
+			return candidate, true, true
+		}
+		if distance >= 0 && distance < bestDistance {
+			destination, bestDistance, ok = candidate, distance, true
+		}
+	}
+	return destination, false, ok
+}
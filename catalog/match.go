@@ -0,0 +1,89 @@
+package catalog
+
+import "strings"
+
+// leadingPrepositions отбрасываются перед поиском: «в Турцию», «на Кипр».
+var leadingPrepositions = []string{"в ", "во ", "на ", "по ", "из ", "со ", "с "}
+
+// Normalize приводит название к виду для сравнения: нижний регистр, «ё» → «е»,
+// дефисы как пробелы, без предлогов в начале и знаков препинания по краям.
+func Normalize(text string) string {
+	lower := strings.ToLower(strings.TrimSpace(text))
+	lower = strings.NewReplacer("ё", "е", "-", " ", "–", " ", "—", " ").Replace(lower)
+	lower = strings.Trim(lower, " .!?\"'«»()")
+	for _, prep := range leadingPrepositions {
+		if trimmed := strings.TrimPrefix(lower, prep); trimmed != lower && trimmed != "" {
+			lower = trimmed
+			break
+		}
+	}
+	return strings.Join(strings.Fields(lower), " ")
+}
+
+// stem отбрасывает гласные окончания, чтобы «Турцию» и «Турции» совпадали
+// с «Турция». Слишком короткие основы не используются.
+func stem(word string) string {
+	stemmed := strings.TrimRight(word, "аеиоуыэюяйь")
+	if len([]rune(stemmed)) < 3 {
+		return word
+	}
+	return stemmed
+}
+
+// maxTypos — сколько опечаток допускается в слове такой длины.
+func maxTypos(word string) int {
+	switch n := len([]rune(word)); {
+	case n < 4:
+		return 0
+	case n < 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance — расстояние редактирования между строками в символах;
+// перестановка соседних букв («Егпиет») считается одной опечаткой.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// matchNames сравнивает нормализованный запрос с названиями записи.
+// Возвращает 0 при точном совпадении (в том числе по основе слова)
+// и число опечаток при нечетком.
+func matchNames(query string, names []string) int {
+	best := -1
+	for _, name := range names {
+		normalized := Normalize(name)
+		if normalized == query || stem(normalized) == stem(query) {
+			return 0
+		}
+		distance := editDistance(query, normalized)
+		if best < 0 || distance < best {
+			best = distance
+		}
+	}
+	return best
+}
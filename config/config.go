@@ -1,30 +1,111 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"pumpkin_travel_tg_bot/i18n"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 )
 
 type Config struct {
-	BotToken      string
-	ManagerChatID int64
-	DebugMode     bool
+	BotToken          string
+	ManagerChatID     int64
+	AdminIDs          []int64
+	DebugMode         bool
+	StateFile         string
+	LeadsFile         string
+	FormFile          string
+	ManagersFile      string
+	ManagerTopics     string
+	TopicsFile        string
+	SettingsFile      string
+	BansFile          string
+	ManagerLanguage   string
+	OutboxInterval    time.Duration
+	ReminderAfter     time.Duration
+	StateTTL          time.Duration
+	ReminderInterval  time.Duration
+	MessageRateLimit  int
+	MessageRateWindow time.Duration
+	SubmitRateLimit   int
+	SubmitRateWindow  time.Duration
+	DuplicateWindow   time.Duration
+	WorkerCount       int
+	PollingTimeout    int
+	WebhookURL        string
+	WebhookListenAddr string
+	WebhookSecret     string
 }
 
+// Режимы тем форума в чатах менеджеров (MANAGER_TOPICS).
+const (
+	// TopicsPerRequest — у каждой заявки своя тема.
+	TopicsPerRequest = "per_request"
+	// TopicsPerDestination — одна тема на страну направления.
+	TopicsPerDestination = "per_destination"
+)
+
 var AppConfig Config
 
+// Периоды фоновых проверок. Они должны быть положительными: time.NewTicker
+// паникует на нулевом и отрицательном периоде.
+const (
+	defaultOutboxInterval   = 15 * time.Second
+	defaultReminderInterval = time.Minute
+)
+
+// webhookSecretRegexp — допустимый Telegram формат secret_token.
+var webhookSecretRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// IsAdmin сообщает, что пользователю доступны служебные команды (ADMIN_IDS).
+func (c Config) IsAdmin(userID int64) bool {
+	for _, id := range c.AdminIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
 func Load() error {
 	if err := godotenv.Load(); err != nil {
 		logrus.Warn("Файл .env не найден, используются переменные окружения")
 	}
 
 	AppConfig = Config{
-		BotToken:      getEnv("BOT_TOKEN", ""),
-		ManagerChatID: getEnvAsInt64("MANAGER_CHAT_ID", 0),
-		DebugMode:     getEnvAsBool("DEBUG_MODE", false),
+		BotToken:          getEnv("BOT_TOKEN", ""),
+		ManagerChatID:     getEnvAsInt64("MANAGER_CHAT_ID", 0),
+		AdminIDs:          getEnvAsInt64List("ADMIN_IDS"),
+		DebugMode:         getEnvAsBool("DEBUG_MODE", false),
+		StateFile:         getEnv("STATE_FILE", "data/states.json"),
+		LeadsFile:         getEnv("LEADS_FILE", "data/leads.json"),
+		FormFile:          os.Getenv("FORM_FILE"),
+		ManagersFile:      os.Getenv("MANAGERS_FILE"),
+		ManagerTopics:     os.Getenv("MANAGER_TOPICS"),
+		TopicsFile:        getEnv("TOPICS_FILE", "data/topics.json"),
+		SettingsFile:      getEnv("SETTINGS_FILE", "data/settings.json"),
+		BansFile:          getEnv("BANS_FILE", "data/bans.json"),
+		ManagerLanguage:   getEnv("MANAGER_LANGUAGE", i18n.Default),
+		OutboxInterval:    getEnvAsDuration("OUTBOX_INTERVAL", defaultOutboxInterval),
+		ReminderAfter:     getEnvAsDuration("REMINDER_AFTER", 2*time.Hour),
+		StateTTL:          getEnvAsDuration("STATE_TTL", 72*time.Hour),
+		ReminderInterval:  getEnvAsDuration("REMINDER_INTERVAL", defaultReminderInterval),
+		MessageRateLimit:  int(getEnvAsInt64("MESSAGE_RATE_LIMIT", 30)),
+		MessageRateWindow: getEnvAsDuration("MESSAGE_RATE_WINDOW", time.Minute),
+		SubmitRateLimit:   int(getEnvAsInt64("SUBMIT_RATE_LIMIT", 5)),
+		SubmitRateWindow:  getEnvAsDuration("SUBMIT_RATE_WINDOW", 24*time.Hour),
+		DuplicateWindow:   getEnvAsDuration("DUPLICATE_WINDOW", 24*time.Hour),
+		WorkerCount:       int(getEnvAsInt64("WORKER_COUNT", 8)),
+		PollingTimeout:    int(getEnvAsInt64("POLLING_TIMEOUT", 60)),
+		WebhookURL:        os.Getenv("WEBHOOK_URL"),
+		WebhookListenAddr: getEnv("WEBHOOK_LISTEN_ADDR", ":8443"),
+		WebhookSecret:     os.Getenv("WEBHOOK_SECRET"),
 	}
 
 	logrus.Infof("Загружена конфигурация: ManagerChatID=%d, DebugMode=%v",
@@ -38,8 +119,47 @@ func validate() error {
 		logrus.Fatal("BOT_TOKEN не установлен")
 	}
 
+	// Без секрета любой, кто знает адрес вебхука, может присылать поддельные
+	// обновления от имени администраторов и менеджеров.
+	if AppConfig.WebhookURL != "" {
+		if AppConfig.WebhookSecret == "" {
+			return fmt.Errorf("WEBHOOK_SECRET обязателен в режиме вебхука (WEBHOOK_URL=%s)", AppConfig.WebhookURL)
+		}
+		if !webhookSecretRegexp.MatchString(AppConfig.WebhookSecret) {
+			return fmt.Errorf("WEBHOOK_SECRET должен состоять из 1–256 символов A-Z, a-z, 0-9, _ и -")
+		}
+	}
+
+	if AppConfig.OutboxInterval <= 0 {
+		logrus.Warnf("OUTBOX_INTERVAL=%s должен быть больше нуля, используется %s",
+			AppConfig.OutboxInterval, defaultOutboxInterval)
+		AppConfig.OutboxInterval = defaultOutboxInterval
+	}
+	if AppConfig.ReminderInterval <= 0 {
+		logrus.Warnf("REMINDER_INTERVAL=%s должен быть больше нуля, используется %s",
+			AppConfig.ReminderInterval, defaultReminderInterval)
+		AppConfig.ReminderInterval = defaultReminderInterval
+	}
+
+	switch AppConfig.ManagerTopics {
+	case "", TopicsPerRequest, TopicsPerDestination:
+	default:
+		logrus.Warnf("Неизвестный режим MANAGER_TOPICS=%s, заявки отправляются без тем", AppConfig.ManagerTopics)
+		AppConfig.ManagerTopics = ""
+	}
+
+	if !i18n.IsSupported(AppConfig.ManagerLanguage) {
+		logrus.Warnf("Неподдерживаемый язык MANAGER_LANGUAGE=%s, карточки заявок будут на языке %s",
+			AppConfig.ManagerLanguage, i18n.Default)
+		AppConfig.ManagerLanguage = i18n.Default
+	}
+
+	if len(AppConfig.AdminIDs) == 0 {
+		logrus.Warn("ADMIN_IDS не установлен: служебные команды и управление блокировками недоступны")
+	}
+
 	if AppConfig.ManagerChatID == 0 {
-		logrus.Error("MANAGER_CHAT_ID не установлен или равен 0. Заявки не будут пересылаться!")
+		logrus.Error("MANAGER_CHAT_ID не установлен или равен 0. Заявки без подходящего менеджера не будут пересылаться!")
 	} else {
 		logrus.Infof("MANAGER_CHAT_ID установлен: %d", AppConfig.ManagerChatID)
 	}
@@ -65,6 +185,25 @@ func getEnvAsInt64(key string, defaultValue int64) int64 {
 	return defaultValue
 }
 
+// getEnvAsInt64List читает список чисел через запятую: «123, 456».
+// Некорректные элементы пропускаются с ошибкой в логе.
+func getEnvAsInt64List(key string) []int64 {
+	var result []int64
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		value, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			logrus.Errorf("Не удалось преобразовать элемент %s=%s в число", key, item)
+			continue
+		}
+		result = append(result, value)
+	}
+	return result
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
@@ -73,3 +212,13 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+		logrus.Errorf("Не удалось преобразовать %s=%s в длительность", key, value)
+	}
+	return defaultValue
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateRequiresWebhookSecret(t *testing.T) {
+	previous := AppConfig
+	t.Cleanup(func() { AppConfig = previous })
+
+	tests := []struct {
+		name    string
+		url     string
+		secret  string
+		wantErr bool
+	}{
+		{"polling without secret", "", "", false},
+		{"webhook without secret", "https://example.com/hook", "", true},
+		{"webhook with invalid secret", "https://example.com/hook", "not allowed!", true},
+		{"webhook with secret", "https://example.com/hook", "Abc_123-xyz", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			AppConfig = Config{
+				BotToken:        "token",
+				ManagerLanguage: "ru",
+				WebhookURL:      tt.url,
+				WebhookSecret:   tt.secret,
+			}
+			if err := validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateResetsNonPositiveIntervals(t *testing.T) {
+	previous := AppConfig
+	t.Cleanup(func() { AppConfig = previous })
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		AppConfig = Config{
+			BotToken:         "token",
+			ManagerLanguage:  "ru",
+			OutboxInterval:   interval,
+			ReminderInterval: interval,
+		}
+		if err := validate(); err != nil {
+			t.Fatalf("validate() error = %v", err)
+		}
+		if AppConfig.OutboxInterval != defaultOutboxInterval {
+			t.Errorf("OutboxInterval = %s, want %s", AppConfig.OutboxInterval, defaultOutboxInterval)
+		}
+		if AppConfig.ReminderInterval != defaultReminderInterval {
+			t.Errorf("ReminderInterval = %s, want %s", AppConfig.ReminderInterval, defaultReminderInterval)
+		}
+	}
+
+	AppConfig = Config{BotToken: "token", ManagerLanguage: "ru", OutboxInterval: time.Hour, ReminderInterval: time.Hour}
+	if err := validate(); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+	if AppConfig.OutboxInterval != time.Hour || AppConfig.ReminderInterval != time.Hour {
+		t.Errorf("positive intervals changed: %s, %s", AppConfig.OutboxInterval, AppConfig.ReminderInterval)
+	}
+}
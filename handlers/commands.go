@@ -1,41 +1,39 @@
 package handlers
 
 import (
+	"pumpkin_travel_tg_bot/i18n"
 	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/questionnaire"
+	"pumpkin_travel_tg_bot/storage"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/sirupsen/logrus"
 )
 
 type CommandHandler struct {
-	bot        *tgbotapi.BotAPI
-	userStates map[int64]*models.TravelRequest
-	userStep   map[int64]int
+	bot      *tgbotapi.BotAPI
+	states   storage.StateStore
+	settings storage.SettingsStore
+	form     *questionnaire.Form
 }
 
-func NewCommandHandler(bot *tgbotapi.BotAPI) *CommandHandler {
+func NewCommandHandler(
+	bot *tgbotapi.BotAPI,
+	states storage.StateStore,
+	settings storage.SettingsStore,
+	form *questionnaire.Form,
+) *CommandHandler {
 	return &CommandHandler{
-		bot:        bot,
-		userStates: make(map[int64]*models.TravelRequest),
-		userStep:   make(map[int64]int),
+		bot:      bot,
+		states:   states,
+		settings: settings,
+		form:     form,
 	}
 }
 
 func (ch *CommandHandler) HandleStart(update tgbotapi.Update) {
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		`🤍 <b>Привет!</b>
-Я — помогаю подобрать путешествия без хлопот и лишней суеты ✈️
-
-Подбираю туры под конкретные даты, бюджет и формат отдыха — так, как подбирала бы для себя.
-
-Ответьте на 10 коротких вопросов, и я предложу подходящие варианты 🌴
-
-<b>Доступные команды:</b>
-/newrequest — Начать оформление новой заявки
-/help — Получить справку
-/cancel — Отменить текущий диалог
-
-Просто нажмите /newrequest, чтобы начать!`)
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, i18n.T(ch.Language(update.Message.From), "start"))
 	msg.ParseMode = "HTML"
 
 	ch.bot.Send(msg)
@@ -46,18 +44,7 @@ func (ch *CommandHandler) HandleStart(update tgbotapi.Update) {
 }
 
 func (ch *CommandHandler) HandleHelp(update tgbotapi.Update) {
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		`<b>Помощь по боту</b>
-
-Этот бот собирает ваши пожелания к путешествию и передает их Ангелине — специалисту по подбору туров.
-
-<b>Как это работает:</b>
-1. Нажмите /newrequest
-2. Ответьте на 10 вопросов о вашем путешествии
-3. После заполнения всех данных заявка автоматически отправится
-4. Ангелина свяжется с вами в ближайшее время с подбором вариантов
-
-Вы можете прервать заполнение заявки командой /cancel в любой момент.`)
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, i18n.T(ch.Language(update.Message.From), "help"))
 	msg.ParseMode = "HTML"
 
 	ch.bot.Send(msg)
@@ -66,13 +53,9 @@ func (ch *CommandHandler) HandleHelp(update tgbotapi.Update) {
 func (ch *CommandHandler) HandleCancel(update tgbotapi.Update) {
 	userID := update.Message.From.ID
 
-	if _, exists := ch.userStates[userID]; exists {
-		delete(ch.userStates, userID)
-		delete(ch.userStep, userID)
-	}
+	ch.resetUserState(userID)
 
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		"❌ Диалог прерван. Ваши данные не сохранены.\n\nЧтобы начать заново, нажмите /newrequest")
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, i18n.T(ch.Language(update.Message.From), "cancel.done"))
 	msg.ParseMode = "HTML"
 
 	ch.bot.Send(msg)
@@ -80,40 +63,87 @@ func (ch *CommandHandler) HandleCancel(update tgbotapi.Update) {
 }
 
 func (ch *CommandHandler) HandleNewRequest(update tgbotapi.Update) {
-	userID := update.Message.From.ID
+	ch.startRequest(update.Message.Chat.ID, update.Message.From.ID, ch.Language(update.Message.From))
+}
 
-	ch.userStates[userID] = &models.TravelRequest{}
-	ch.userStep[userID] = STEP_DESTINATION
+func (ch *CommandHandler) startRequest(chatID, userID int64, lang string) {
+	state := &storage.UserState{
+		Request: models.TravelRequest{CreatedAt: time.Now(), Language: lang},
+	}
+	first := ch.form.First()
+	ch.UpdateUserStep(userID, state, first.Key)
+	ch.askQuestion(chatID, 0, ch.form.IntroText(lang)+"\n\n", first, state)
 
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		`🌴 <b>Отлично! Давайте подберем для вас идеальное путешествие.</b>
+	logrus.WithField("user_id", userID).Info("Начался новый диалог с пользователем")
+}
 
-Я задам 10 вопросов, это займет 2-3 минуты.
+// Language возвращает язык, на котором бот говорит с пользователем: выбранный
+// командой /language или, если язык не выбирался, язык его Telegram.
+func (ch *CommandHandler) Language(user *tgbotapi.User) string {
+	if user == nil {
+		return i18n.Default
+	}
 
-1️⃣
-<b>Куда планируете поездку?</b>
-(Написать интересные вам направления)
+	settings, err := ch.settings.Get(user.ID)
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", user.ID).Error("Ошибка чтения настроек пользователя")
+	}
+	if settings.Language != "" && i18n.IsSupported(settings.Language) {
+		return settings.Language
+	}
+	return i18n.Detect(user.LanguageCode)
+}
 
-<code>Пример: Турция / Россия / Пока не определились</code>
+func (ch *CommandHandler) GetUserState(userID int64) (*storage.UserState, bool) {
+	state, exists, err := ch.states.Get(userID)
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Error("Ошибка чтения состояния диалога")
+		return nil, false
+	}
 
-<em>Если нет конкретной страны — подберу варианты</em>`)
-	msg.ParseMode = "HTML"
+	return state, exists
+}
 
-	ch.bot.Send(msg)
-	logrus.WithField("user_id", userID).Info("Начался новый диалог с пользователем")
+// UpdateUserStep сохраняет заполненные ответы и переводит диалог на указанный шаг.
+// При смене шага черновики ответов кнопками сбрасываются. Сохранение считается
+// активностью клиента: отсчет до напоминания о брошенной анкете начинается заново.
+func (ch *CommandHandler) UpdateUserStep(userID int64, state *storage.UserState, step string) {
+	if state.Step != step {
+		ch.resetAnswerDraft(state, step)
+	}
+	state.Step = step
+	state.UpdatedAt = time.Now()
+	state.RemindedAt = time.Time{}
+	if err := ch.states.Put(userID, *state); err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Error("Ошибка сохранения состояния диалога")
+	}
 }
 
-func (ch *CommandHandler) GetUserState(userID int64) (*models.TravelRequest, int, bool) {
-	state, stateExists := ch.userStates[userID]
-	step, stepExists := ch.userStep[userID]
+// resetAnswerDraft готовит черновик ответа для нового шага: счетчики туристов
+// начинают с уже известного состава, а возраст детей собирается заново.
+func (ch *CommandHandler) resetAnswerDraft(state *storage.UserState, step string) {
+	state.Selected = nil
+	state.Suggestion = nil
+	state.Adults = defaultAdults
+	state.Children = 0
+
+	party := state.Request.Party
+	if party == nil {
+		return
+	}
 
-	if !stateExists || !stepExists {
-		return nil, 0, false
+	if party.Adults > 0 {
+		state.Adults = party.Adults
 	}
+	state.Children = party.Children
 
-	return state, step, true
+	if q, ok := ch.form.Question(step); ok && q.Type == questionnaire.InputChildAges {
+		party.ChildAges = nil
+	}
 }
 
-func (ch *CommandHandler) UpdateUserStep(userID int64, step int) {
-	ch.userStep[userID] = step
+func (ch *CommandHandler) resetUserState(userID int64) {
+	if err := ch.states.Delete(userID); err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Error("Ошибка удаления состояния диалога")
+	}
 }
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"pumpkin_travel_tg_bot/i18n"
+	"strings"
+	"unicode"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	confirmCallbackPrefix = "confirm_"
+	confirmYesCallback    = "confirm_yes"
+	confirmNoCallback     = "confirm_no"
+)
+
+type confirmAnswer int
+
+const (
+	confirmUnknown confirmAnswer = iota
+	confirmYes
+	confirmNo
+)
+
+// Ответ сравнивается целиком, а не поиском подстроки: иначе «да» находится
+// в словах вроде «когда-нибудь».
+var (
+	yesAnswers = map[string]bool{
+		"да": true, "yes": true, "ок": true, "ok": true, "ага": true, "конечно": true,
+		"подтверждаю": true, "верно": true, "все верно": true, "да все верно": true,
+		"отправить": true, "отправляй": true, "отправляйте": true,
+		"yep": true, "sure": true, "correct": true, "send": true, "confirm": true,
+	}
+	noAnswers = map[string]bool{
+		"нет": true, "no": true, "неверно": true, "не верно": true,
+		"заново": true, "перезаполнить": true, "заполнить заново": true,
+		"nope": true, "wrong": true, "restart": true, "start over": true,
+	}
+)
+
+func confirmationKeyboard(lang string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.send"), confirmYesCallback),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.restart"), confirmNoCallback),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.edit"), editMenuCallback),
+		),
+	)
+}
+
+// parseConfirmation распознает ответ на вопрос «Всё верно?» на любом из языков бота.
+func parseConfirmation(text string) confirmAnswer {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	answer := strings.ReplaceAll(strings.Join(words, " "), "ё", "е")
+
+	switch {
+	case yesAnswers[answer]:
+		return confirmYes
+	case noAnswers[answer]:
+		return confirmNo
+	}
+	return confirmUnknown
+}
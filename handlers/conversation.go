@@ -1,9 +1,13 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
+	"html"
+	"pumpkin_travel_tg_bot/i18n"
 	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/questionnaire"
 	"pumpkin_travel_tg_bot/services"
+	"pumpkin_travel_tg_bot/storage"
 	"strings"
 	"time"
 
@@ -26,28 +30,13 @@ func NewConversationHandler(
 	}
 }
 
-const (
-	STEP_DESTINATION = iota + 1
-	STEP_DEPARTURE_CITY
-	STEP_TRAVEL_DATES
-	STEP_DURATION
-	STEP_TRAVELERS
-	STEP_CHILD_AGE
-	STEP_BUDGET
-	STEP_VACATION_TYPE
-	STEP_HOTEL_LEVEL
-	STEP_MEAL_PLAN
-	STEP_IMPORTANT_FACTORS
-	STEP_CONFIRMATION
-)
+// StepConfirmation — шаг проверки заполненной заявки перед отправкой.
+// Остальные шаги диалога совпадают с ключами вопросов анкеты.
+const StepConfirmation = "confirmation"
 
 func (ch *ConversationHandler) HandleMessage(update tgbotapi.Update) {
 	if update.CallbackQuery != nil {
-		userID := update.CallbackQuery.From.ID
-		state, step, exists := ch.commandHandler.GetUserState(userID)
-		if exists && step == STEP_HOTEL_LEVEL {
-			ch.handleHotelLevel(update, state, userID)
-		}
+		ch.handleCallback(update)
 		return
 	}
 
@@ -57,369 +46,485 @@ func (ch *ConversationHandler) HandleMessage(update tgbotapi.Update) {
 
 	userID := update.Message.From.ID
 
-	state, step, exists := ch.commandHandler.GetUserState(userID)
+	state, exists := ch.commandHandler.GetUserState(userID)
 	if !exists {
-		ch.commandHandler.HandleHelp(update)
+		if !ch.relayToManager(update) {
+			ch.commandHandler.HandleHelp(update)
+		}
 		return
 	}
 
-	switch step {
-	case STEP_DESTINATION:
-		ch.handleDestination(update, state, userID)
-	case STEP_DEPARTURE_CITY:
-		ch.handleDepartureCity(update, state, userID)
-	case STEP_TRAVEL_DATES:
-		ch.handleTravelDates(update, state, userID)
-	case STEP_DURATION:
-		ch.handleDuration(update, state, userID)
-	case STEP_TRAVELERS:
-		ch.handleTravelers(update, state, userID)
-	case STEP_CHILD_AGE:
-		ch.handleChildAge(update, state, userID)
-	case STEP_BUDGET:
-		ch.handleBudget(update, state, userID)
-	case STEP_VACATION_TYPE:
-		ch.handleVacationType(update, state, userID)
-	case STEP_HOTEL_LEVEL:
-		ch.handleHotelLevel(update, state, userID)
-	case STEP_MEAL_PLAN:
-		ch.handleMealPlan(update, state, userID)
-	case STEP_IMPORTANT_FACTORS:
-		ch.handleImportantFactors(update, state, userID)
-	case STEP_CONFIRMATION:
+	lang := state.Request.Language
+	if state.Step == StepConfirmation {
+		if nonTextKind(update.Message) != "" {
+			ch.commandHandler.sendOrEdit(update.Message.Chat.ID, 0, i18n.T(lang, "confirm.reply_hint"), nil)
+			return
+		}
 		ch.handleConfirmation(update, state, userID)
-	default:
-		ch.resetUserState(userID)
+		return
 	}
-}
 
-func (ch *ConversationHandler) handleDestination(update tgbotapi.Update, state *models.TravelRequest, userID int64) {
-	state.Destination = update.Message.Text
-	ch.commandHandler.UpdateUserStep(userID, STEP_DEPARTURE_CITY)
+	question, ok := ch.commandHandler.form.Question(state.Step)
+	if !ok {
+		logrus.WithFields(logrus.Fields{
+			"user_id": userID,
+			"step":    state.Step,
+		}).Warn("Шаг диалога отсутствует в анкете, диалог сброшен")
+		ch.resetUserState(userID)
+		return
+	}
 
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		`2️⃣
-<b>Из какого города планируется вылет?</b>
-(Напишите ваш город или из которого хотите вылететь)
+	if kind := nonTextKind(update.Message); kind != "" {
+		ch.commandHandler.askQuestion(update.Message.Chat.ID, 0,
+			i18n.T(lang, "question.non_text", i18n.T(lang, "kind."+kind))+"\n\n", question, state)
+		return
+	}
 
-<code>Например: Москва, Краснодар или Сочи</code>`)
-	msg.ParseMode = "HTML"
-	ch.commandHandler.bot.Send(msg)
+	ch.handleAnswer(update.Message.Chat.ID, 0, state, userID, question, update.Message.Text)
 }
 
-func (ch *ConversationHandler) handleDepartureCity(update tgbotapi.Update, state *models.TravelRequest, userID int64) {
-	state.DepartureCity = update.Message.Text
-	ch.commandHandler.UpdateUserStep(userID, STEP_TRAVEL_DATES)
-
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		`3️⃣
-<b>Желаемые даты поездки</b>
-(Напишите точные даты или примерные)
-
-<code>Например:
-10–20 мая
-Июнь
-Любые даты февраля
-Самые бюджетные на следующий месяц</code>`)
-	msg.ParseMode = "HTML"
-	ch.commandHandler.bot.Send(msg)
+// relayToManager пересылает сообщение клиента вне анкеты менеджеру по его
+// открытой заявке. Возвращает false, если открытых заявок у клиента нет.
+func (ch *ConversationHandler) relayToManager(update tgbotapi.Update) bool {
+	chatID := update.Message.Chat.ID
+	lang := ch.commandHandler.Language(update.Message.From)
+
+	lead, err := ch.formService.RelayToManager(update.Message)
+	switch {
+	case errors.Is(err, services.ErrLeadNotFound):
+		return false
+	case err != nil:
+		logrus.WithError(err).WithField("user_id", update.Message.From.ID).Error("Ошибка пересылки сообщения менеджеру")
+		ch.commandHandler.sendOrEdit(chatID, 0, i18n.T(lang, "relay.failed"), nil)
+	default:
+		ch.commandHandler.sendOrEdit(chatID, 0, i18n.T(lang, "relay.sent", lead.Number), nil)
+	}
+	return true
 }
 
-func (ch *ConversationHandler) handleTravelDates(update tgbotapi.Update, state *models.TravelRequest, userID int64) {
-	state.TravelDates = update.Message.Text
-	ch.commandHandler.UpdateUserStep(userID, STEP_DURATION)
-
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		`4️⃣
-<b>Сколько дней планируете отдых?</b>
-(Напишите точное или примерное количество)
+// HandleBack возвращает клиента к предыдущему вопросу анкеты. Во время правки
+// отдельного ответа /back возвращает к подтверждению заявки.
+func (ch *ConversationHandler) HandleBack(update tgbotapi.Update) {
+	userID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
 
-<code>Например: 3 дня / неделя / 10–14 дней</code>`)
-	msg.ParseMode = "HTML"
-	ch.commandHandler.bot.Send(msg)
-}
+	state, exists := ch.commandHandler.GetUserState(userID)
+	if !exists {
+		ch.commandHandler.sendOrEdit(chatID, 0, i18n.T(ch.commandHandler.Language(update.Message.From), "back.no_request"), nil)
+		return
+	}
+	lang := state.Request.Language
 
-func (ch *ConversationHandler) handleDuration(update tgbotapi.Update, state *models.TravelRequest, userID int64) {
-	state.Duration = update.Message.Text
-	ch.commandHandler.UpdateUserStep(userID, STEP_TRAVELERS)
-
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		`5️⃣
-<b>Сколько человек летит?</b>
-(Напишите количество туристов)
-
-<code>Например:
-2 взрослых
-2 взрослых + 1 ребёнок
-1 взрослый</code>`)
-	msg.ParseMode = "HTML"
-	ch.commandHandler.bot.Send(msg)
-}
+	if state.Editing {
+		ch.showConfirmation(chatID, 0, "", state, userID)
+		return
+	}
 
-func (ch *ConversationHandler) handleTravelers(update tgbotapi.Update, state *models.TravelRequest, userID int64) {
-	state.Travelers = update.Message.Text
+	if len(state.History) == 0 {
+		question, ok := ch.commandHandler.form.Question(state.Step)
+		if !ok {
+			question = ch.commandHandler.form.First()
+		}
+		ch.commandHandler.askQuestion(chatID, 0, i18n.T(lang, "back.first_question")+"\n\n", question, state)
+		return
+	}
 
-	answer := strings.ToLower(update.Message.Text)
-	if strings.Contains(answer, "ребен") || strings.Contains(answer, "дет") {
-		ch.commandHandler.UpdateUserStep(userID, STEP_CHILD_AGE)
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			`<b>Сколько лет ребенку?</b>
-(Напишите возраст)
+	previousKey := state.History[len(state.History)-1]
+	state.History = state.History[:len(state.History)-1]
 
-<code>Например: 3 года / 5 / 12 лет</code>`)
-		msg.ParseMode = "HTML"
-		ch.commandHandler.bot.Send(msg)
-	} else {
-		state.ChildAge = "Нет детей"
-		ch.commandHandler.UpdateUserStep(userID, STEP_BUDGET)
+	question, ok := ch.commandHandler.form.Question(previousKey)
+	if !ok {
+		ch.resetUserState(userID)
+		return
+	}
 
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			`6️⃣
-<b>Бюджет на всех (перелёт + проживание)</b>
-(Напишите планируемый бюджет)
+	ch.commandHandler.UpdateUserStep(userID, state, question.Key)
 
-<code>Например:
-до 80 000 ₽
-200–250 тыс.
-Без строгих рамок</code>`)
-		msg.ParseMode = "HTML"
-		ch.commandHandler.bot.Send(msg)
+	prefix := ""
+	if answer := state.Request.Field(question.Key); answer != "" {
+		prefix = i18n.T(lang, "back.previous_answer", html.EscapeString(question.AnswerText(lang, answer))) + "\n\n"
 	}
-}
+	ch.commandHandler.askQuestion(chatID, 0, prefix, question, state)
 
-func (ch *ConversationHandler) handleChildAge(update tgbotapi.Update, state *models.TravelRequest, userID int64) {
-	state.ChildAge = update.Message.Text
-	ch.commandHandler.UpdateUserStep(userID, STEP_BUDGET)
-
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		`6️⃣
-<b>Бюджет на всех (перелёт + проживание)</b>
-(Напишите планируемый бюджет)
-
-<code>Например:
-до 80 000 ₽
-200–250 тыс.
-Без строгих рамок</code>`)
-	msg.ParseMode = "HTML"
-	ch.commandHandler.bot.Send(msg)
+	logrus.WithFields(logrus.Fields{
+		"user_id": userID,
+		"step":    question.Key,
+	}).Debug("Пользователь вернулся к предыдущему вопросу")
 }
 
-func (ch *ConversationHandler) handleBudget(update tgbotapi.Update, state *models.TravelRequest, userID int64) {
-	state.Budget = update.Message.Text
-	ch.commandHandler.UpdateUserStep(userID, STEP_VACATION_TYPE)
-
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		`7️⃣
-<b>Какой отдых вы хотите?</b>
-(Напишите все пожелания по отдыху)
-
-<code>Например:
-Пляжный
-Пляж + экскурсии + все включено
-Активный без детей
-Спокойный / релакс
-С детьми</code>`)
-	msg.ParseMode = "HTML"
-	ch.commandHandler.bot.Send(msg)
-}
+// handleResumeCallback по кнопке из напоминания заново задает текущий вопрос анкеты.
+func (ch *ConversationHandler) handleResumeCallback(query *tgbotapi.CallbackQuery, state *storage.UserState, userID int64) {
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+	ch.answerCallback(query.ID, "")
 
-func (ch *ConversationHandler) handleVacationType(update tgbotapi.Update, state *models.TravelRequest, userID int64) {
-	state.VacationType = update.Message.Text
-	ch.commandHandler.UpdateUserStep(userID, STEP_HOTEL_LEVEL)
-
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		`8️⃣
-<b>Какой уровень отеля рассматриваете?</b>
-
-Выберите вариант ниже или напишите свой:`)
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("3★", "hotel_3"),
-			tgbotapi.NewInlineKeyboardButtonData("4★", "hotel_4"),
-			tgbotapi.NewInlineKeyboardButtonData("5★", "hotel_5"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Любой уровень", "hotel_any"),
-			tgbotapi.NewInlineKeyboardButtonData("Не имеет значения", "hotel_no_matter"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("3★ или 4★", "hotel_3_4"),
-			tgbotapi.NewInlineKeyboardButtonData("4★ или 5★", "hotel_4_5"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Отель 16+", "hotel_16"),
-			tgbotapi.NewInlineKeyboardButtonData("Отель 18+", "hotel_18"),
-		),
-	)
-
-	msg.ReplyMarkup = keyboard
-	msg.ParseMode = "HTML"
-
-	ch.commandHandler.bot.Send(msg)
-}
+	if state.Step == StepConfirmation {
+		ch.showConfirmation(chatID, messageID, "", state, userID)
+		return
+	}
 
-func (ch *ConversationHandler) handleHotelLevel(update tgbotapi.Update, state *models.TravelRequest, userID int64) {
-	if update.CallbackQuery != nil {
-		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "")
-		if _, err := ch.commandHandler.bot.Request(callback); err != nil {
-			logrus.Error("Ошибка отправки callback:", err)
-		}
+	question, ok := ch.commandHandler.form.Question(state.Step)
+	if !ok {
+		ch.resetUserState(userID)
+		ch.commandHandler.sendOrEdit(chatID, messageID, i18n.T(state.Request.Language, "resume.form_changed"), nil)
+		return
+	}
 
-		callbackData := update.CallbackQuery.Data
-		var hotelLevelText string
-
-		switch callbackData {
-		case "hotel_3":
-			hotelLevelText = "3★"
-		case "hotel_4":
-			hotelLevelText = "4★"
-		case "hotel_5":
-			hotelLevelText = "5★"
-		case "hotel_any":
-			hotelLevelText = "Любой уровень"
-		case "hotel_no_matter":
-			hotelLevelText = "Не имеет значения"
-		case "hotel_3_4":
-			hotelLevelText = "3★ или 4★"
-		case "hotel_4_5":
-			hotelLevelText = "4★ или 5★"
-		case "hotel_16":
-			hotelLevelText = "Отель 16+"
-		case "hotel_18":
-			hotelLevelText = "Отель 18+"
-		default:
-			hotelLevelText = "Не указано"
-		}
+	ch.commandHandler.UpdateUserStep(userID, state, state.Step)
+	ch.commandHandler.askQuestion(chatID, messageID, i18n.T(state.Request.Language, "resume.continue")+"\n\n", question, state)
+}
 
-		state.HotelLevel = hotelLevelText
+func (ch *ConversationHandler) handleCallback(update tgbotapi.Update) {
+	query := update.CallbackQuery
+	userID := query.From.ID
 
-		editMsg := tgbotapi.NewEditMessageText(
-			update.CallbackQuery.Message.Chat.ID,
-			update.CallbackQuery.Message.MessageID,
-			fmt.Sprintf(`✅ <b>Выбрано:</b> %s
+	// Кнопки списка заявок и выбора языка работают и без активного диалога.
+	switch {
+	case strings.HasPrefix(query.Data, myRequestsCallbackPrefix):
+		ch.handleMyRequestsCallback(query)
+		return
+	case strings.HasPrefix(query.Data, languageCallbackPrefix):
+		ch.handleLanguageCallback(query)
+		return
+	}
 
-9️⃣
-<b>Желаемый тип питания</b>
+	state, exists := ch.commandHandler.GetUserState(userID)
+	if !exists {
+		ch.answerCallback(query.ID, i18n.T(ch.commandHandler.Language(query.From), "callback.inactive"))
+		return
+	}
 
-<code>Наример:
-Завтрак
-Обед
-Завтрак + ужин
-Всё включено
-Без разницы</code>`, hotelLevelText),
-		)
-		editMsg.ParseMode = "HTML"
-		editMsg.ReplyMarkup = nil
+	switch {
+	case strings.HasPrefix(query.Data, editCallbackPrefix):
+		ch.handleEditCallback(query, state, userID)
+	case strings.HasPrefix(query.Data, confirmCallbackPrefix):
+		ch.handleConfirmCallback(query, state, userID)
+	case strings.HasPrefix(query.Data, suggestionCallbackPrefix):
+		ch.handleSuggestionCallback(query, state, userID)
+	case strings.HasPrefix(query.Data, keepCallbackPrefix):
+		ch.handleKeepCallback(query, state, userID)
+	case query.Data == noopCallback:
+		ch.answerCallback(query.ID, "")
+	case query.Data == services.ResumeCallback:
+		ch.handleResumeCallback(query, state, userID)
+	default:
+		ch.handleQuestionCallback(query, state, userID)
+	}
+}
 
-		ch.commandHandler.bot.Send(editMsg)
+// handleQuestionCallback обрабатывает кнопки ответа на текущий вопрос анкеты.
+func (ch *ConversationHandler) handleQuestionCallback(query *tgbotapi.CallbackQuery, state *storage.UserState, userID int64) {
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+	lang := state.Request.Language
 
-		ch.commandHandler.UpdateUserStep(userID, STEP_MEAL_PLAN)
+	question, ok := ch.commandHandler.form.Question(state.Step)
+	if !ok {
+		ch.answerCallback(query.ID, i18n.T(lang, "callback.wrong_step"))
+		return
+	}
 
-	} else if update.Message != nil {
-		state.HotelLevel = update.Message.Text
-		ch.commandHandler.UpdateUserStep(userID, STEP_MEAL_PLAN)
+	switch {
+	case question.Type == questionnaire.InputChoice:
+		answer, ok := selectedOption(question, query.Data)
+		if !ok {
+			ch.answerCallback(query.ID, i18n.T(lang, "callback.wrong_step"))
+			return
+		}
+		ch.answerCallback(query.ID, "")
+		ch.handleAnswer(chatID, messageID, state, userID, question, answer)
 
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			`9️⃣
-<b>Желаемый тип питания</b>
+	case question.Type == questionnaire.InputMultiChoice && query.Data == optionsDoneCallback(question):
+		if len(state.Selected) == 0 {
+			ch.answerCallback(query.ID, i18n.T(lang, "callback.select_one"))
+			return
+		}
+		ch.answerCallback(query.ID, "")
+		ch.handleAnswer(chatID, messageID, state, userID, question, joinSelected(state.Selected))
+
+	case question.Type == questionnaire.InputMultiChoice:
+		option, ok := selectedOption(question, query.Data)
+		if !ok {
+			ch.answerCallback(query.ID, i18n.T(lang, "callback.wrong_step"))
+			return
+		}
+		ch.answerCallback(query.ID, "")
+		state.Selected = toggleOption(question, state.Selected, option)
+		ch.commandHandler.UpdateUserStep(userID, state, state.Step)
+		ch.commandHandler.editKeyboard(chatID, messageID, questionKeyboard(question, state))
 
-<code>Наример:
-Завтрак
-Обед
-Завтрак + ужин
-Всё включено
-Без разницы</code>`)
-		msg.ParseMode = "HTML"
-		ch.commandHandler.bot.Send(msg)
+	case question.Type == questionnaire.InputTravelers && strings.HasPrefix(query.Data, counterCallbackPrefix):
+		ch.handleCounterCallback(query, state, userID, question)
+
+	default:
+		ch.answerCallback(query.ID, i18n.T(lang, "callback.wrong_step"))
 	}
 }
 
-func (ch *ConversationHandler) handleMealPlan(update tgbotapi.Update, state *models.TravelRequest, userID int64) {
-	state.MealPlan = update.Message.Text
-	ch.commandHandler.UpdateUserStep(userID, STEP_IMPORTANT_FACTORS)
+// handleCounterCallback меняет счетчики туристов или, по кнопке «Готово»,
+// записывает состав туристов как ответ.
+func (ch *ConversationHandler) handleCounterCallback(
+	query *tgbotapi.CallbackQuery,
+	state *storage.UserState,
+	userID int64,
+	question *questionnaire.Question,
+) {
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+	lang := state.Request.Language
+
+	switch query.Data {
+	case countersDoneCallback:
+		ch.answerCallback(query.ID, "")
+		ch.handleAnswer(chatID, messageID, state, userID, question, formatTravelers(lang, state.Adults, state.Children))
+		return
+	case adultsIncCallback:
+		if state.Adults >= maxAdults {
+			ch.answerCallback(query.ID, i18n.T(lang, "counters.max_adults", maxAdults))
+			return
+		}
+		state.Adults++
+	case adultsDecCallback:
+		if state.Adults <= minAdults {
+			ch.answerCallback(query.ID, i18n.T(lang, "counters.min_adults"))
+			return
+		}
+		state.Adults--
+	case childrenIncCallback:
+		if state.Children >= maxChildren {
+			ch.answerCallback(query.ID, i18n.T(lang, "counters.max_children", maxChildren))
+			return
+		}
+		state.Children++
+	case childrenDecCallback:
+		if state.Children <= 0 {
+			ch.answerCallback(query.ID, "")
+			return
+		}
+		state.Children--
+	default:
+		ch.answerCallback(query.ID, i18n.T(lang, "callback.wrong_step"))
+		return
+	}
 
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		`🔟
-<b>Что для вас принципиально важно?</b>
+	ch.answerCallback(query.ID, "")
+	ch.commandHandler.UpdateUserStep(userID, state, state.Step)
+	ch.commandHandler.editKeyboard(chatID, messageID, questionKeyboard(question, state))
+}
 
-<code>Например:
-Первая линия
-Песчаный пляж
-Хороший Wi-Fi
-Без пересадок
-Свой бассейн</code>
+// handleAnswer проверяет ответ на текущий вопрос и, если в нем нет ошибок
+// и опечаток, сохраняет его. messageID задан, если ответ выбран кнопкой:
+// тогда следующий вопрос заменяет сообщение с кнопками.
+func (ch *ConversationHandler) handleAnswer(
+	chatID int64,
+	messageID int,
+	state *storage.UserState,
+	userID int64,
+	question *questionnaire.Question,
+	answer string,
+) {
+	// Новый ответ текстом отменяет предложенное ранее исправление.
+	state.Suggestion = nil
+
+	if err := question.Validate(answer, &state.Request); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id": userID,
+			"step":    question.Key,
+		}).Debug("Ответ не прошел проверку")
+
+		ch.commandHandler.askQuestion(chatID, 0, "❗ "+validationMessage(err, state.Request.Language)+"\n\n", question, state)
+		return
+	}
+
+	if options := suggestAnswer(question.Key, answer); len(options) > 0 {
+		ch.askSuggestion(chatID, state, userID, answer, options)
+		return
+	}
 
-<em>Если ничего не принципиально — напишите "нет"</em>`)
-	msg.ParseMode = "HTML"
-	ch.commandHandler.bot.Send(msg)
+	ch.acceptAnswer(chatID, messageID, state, userID, question, answer)
 }
 
-func (ch *ConversationHandler) handleImportantFactors(update tgbotapi.Update, state *models.TravelRequest, userID int64) {
-	state.ImportantFactors = update.Message.Text
-	state.CreatedAt = time.Now()
-	ch.commandHandler.UpdateUserStep(userID, STEP_CONFIRMATION)
+// acceptAnswer сохраняет проверенный ответ на текущий вопрос и задает следующий.
+func (ch *ConversationHandler) acceptAnswer(
+	chatID int64,
+	messageID int,
+	state *storage.UserState,
+	userID int64,
+	question *questionnaire.Question,
+	answer string,
+) {
+	if question.Type == questionnaire.InputChildAges {
+		var complete bool
+		if answer, complete = collectChildAge(&state.Request, answer); !complete {
+			ch.commandHandler.UpdateUserStep(userID, state, question.Key)
+			ch.commandHandler.askQuestion(chatID, 0, "", question, state)
+			return
+		}
+	}
 
-	preview := state.ToClientPreview()
+	// Варианты ответа, написанные текстом, записываются на языке анкеты, как и выбранные кнопкой.
+	answer = question.CanonicalAnswer(answer)
+	state.Request.SetField(question.Key, question.Label, answer)
+	parseAnswer(&state.Request, question.Key, answer)
 
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		fmt.Sprintf(`<b>✅ Все готово! Проверьте вашу заявку:</b>
+	prefix := ""
+	if messageID != 0 {
+		lang := state.Request.Language
+		prefix = i18n.T(lang, "answer.selected", html.EscapeString(question.AnswerText(lang, answer))) + "\n\n"
+	}
 
-%s
+	var next *questionnaire.Question
+	if state.Editing {
+		next = ch.commandHandler.form.NextUnanswered(&state.Request)
+	} else {
+		state.History = append(state.History, question.Key)
+		next = ch.commandHandler.form.Next(question.Key, &state.Request)
+	}
+
+	if next == nil {
+		ch.showConfirmation(chatID, messageID, prefix, state, userID)
+		return
+	}
 
-<b>Всё верно?</b> Отправьте <b>"да"</b> для подтверждения или <b>"нет"</b> для перезаполнения.`, preview))
-	msg.ParseMode = "HTML"
-	ch.commandHandler.bot.Send(msg)
+	ch.commandHandler.UpdateUserStep(userID, state, next.Key)
+	ch.commandHandler.askQuestion(chatID, messageID, prefix, next, state)
 }
 
-func (ch *ConversationHandler) handleConfirmation(update tgbotapi.Update, state *models.TravelRequest, userID int64) {
-	answer := strings.ToLower(update.Message.Text)
+func (ch *ConversationHandler) showConfirmation(chatID int64, messageID int, prefix string, state *storage.UserState, userID int64) {
+	if state.Request.CreatedAt.IsZero() {
+		state.Request.CreatedAt = time.Now()
+	}
+	state.Editing = false
+	// Дальше ответы правятся через «Изменить», прошлая заявка больше не нужна.
+	state.Previous = nil
+	ch.commandHandler.UpdateUserStep(userID, state, StepConfirmation)
+
+	lang := state.Request.Language
+	keyboard := confirmationKeyboard(lang)
+	ch.commandHandler.sendOrEdit(chatID, messageID,
+		prefix+i18n.T(lang, "confirm.text", state.Request.ToClientPreview()), &keyboard)
+}
 
-	if strings.Contains(answer, "да") || strings.Contains(answer, "yes") || answer == "ок" || answer == "подтверждаю" {
-		userInfo := models.UserInfo{
-			ID:        update.Message.From.ID,
-			FirstName: update.Message.From.FirstName,
-			LastName:  update.Message.From.LastName,
-			Username:  update.Message.From.UserName,
-		}
+// validationMessage возвращает текст ошибки в ответе на языке клиента.
+func validationMessage(err error, lang string) string {
+	var validationErr *questionnaire.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Message(lang)
+	}
+	return err.Error()
+}
 
-		if err := ch.formService.SendToManager(*state, userInfo); err != nil {
-			logrus.WithError(err).Error("Ошибка при отправке заявки менеджеру")
+// nonTextKind возвращает вид сообщения без текста — часть ключа kind.<вид>
+// в каталоге переводов — и пустую строку для обычного текстового ответа.
+func nonTextKind(message *tgbotapi.Message) string {
+	switch {
+	case message.Text != "":
+		return ""
+	case message.Photo != nil:
+		return "photo"
+	case message.Sticker != nil:
+		return "sticker"
+	case message.Voice != nil, message.Audio != nil:
+		return "audio"
+	case message.Video != nil, message.VideoNote != nil, message.Animation != nil:
+		return "video"
+	case message.Document != nil:
+		return "document"
+	case message.Location != nil:
+		return "location"
+	case message.Contact != nil:
+		return "contact"
+	}
+	return "other"
+}
 
-			msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-				"❌ Произошла ошибка при отправке заявки. Пожалуйста, попробуйте позже.")
-			ch.commandHandler.bot.Send(msg)
-		} else {
-			msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-				`✅ <b>Спасибо! Ваша заявка отправлена Ангелине.</b>
+func (ch *ConversationHandler) answerCallback(callbackID, text string) {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	if _, err := ch.commandHandler.bot.Request(callback); err != nil {
+		logrus.Error("Ошибка отправки callback:", err)
+	}
+}
 
-Ангелина свяжется с вами в ближайшее время для подбора лучших вариантов.
+func (ch *ConversationHandler) handleConfirmation(update tgbotapi.Update, state *storage.UserState, userID int64) {
+	lang := state.Request.Language
+	switch parseConfirmation(update.Message.Text) {
+	case confirmYes:
+		ch.submitRequest(update.Message.Chat.ID, update.Message.From, state, userID)
+	case confirmNo:
+		ch.resetUserState(userID)
+		ch.commandHandler.startRequest(update.Message.Chat.ID, userID, lang)
+	default:
+		keyboard := confirmationKeyboard(lang)
+		ch.commandHandler.sendOrEdit(update.Message.Chat.ID, 0, i18n.T(lang, "confirm.reply_hint"), &keyboard)
+	}
+}
 
-Для оформления новой заявки нажмите /newrequest`)
-			msg.ParseMode = "HTML"
-			ch.commandHandler.bot.Send(msg)
+// handleConfirmCallback обрабатывает кнопки «Отправить» и «Заполнить заново» под заявкой.
+func (ch *ConversationHandler) handleConfirmCallback(query *tgbotapi.CallbackQuery, state *storage.UserState, userID int64) {
+	if state.Step != StepConfirmation {
+		ch.answerCallback(query.ID, i18n.T(state.Request.Language, "callback.wrong_step"))
+		return
+	}
 
-			logrus.WithFields(logrus.Fields{
-				"user_id":  userID,
-				"username": userInfo.Username,
-			}).Info("Заявка успешно отправлена")
-		}
+	chatID := query.Message.Chat.ID
+	ch.answerCallback(query.ID, "")
+	// Убираем кнопки, чтобы заявку нельзя было отправить повторно.
+	ch.commandHandler.editKeyboard(chatID, query.Message.MessageID, nil)
 
+	switch query.Data {
+	case confirmYesCallback:
+		ch.submitRequest(chatID, query.From, state, userID)
+	case confirmNoCallback:
 		ch.resetUserState(userID)
+		ch.commandHandler.startRequest(chatID, userID, state.Request.Language)
+	}
+}
 
-	} else if strings.Contains(answer, "нет") || strings.Contains(answer, "no") {
-		ch.resetUserState(userID)
-		ch.commandHandler.HandleNewRequest(update)
+func (ch *ConversationHandler) submitRequest(chatID int64, from *tgbotapi.User, state *storage.UserState, userID int64) {
+	userInfo := models.UserInfo{
+		ID:        from.ID,
+		FirstName: from.FirstName,
+		LastName:  from.LastName,
+		Username:  from.UserName,
+	}
 
+	lang := state.Request.Language
+	lead, err := ch.formService.SubmitRequest(state.Request, userInfo)
+	switch {
+	case errors.Is(err, services.ErrDuplicateSubmission):
+		// Анкета сохраняется: чаще всего это неизмененная копия открытой заявки
+		// из «Повторить», и клиент может поправить в ней ответы.
+		keyboard := confirmationKeyboard(lang)
+		ch.commandHandler.sendOrEdit(chatID, 0, i18n.T(lang, "submit.duplicate", lead.Number), &keyboard)
+		return
+	case errors.Is(err, services.ErrSubmitRateLimited):
+		// Анкета сохраняется: ее можно будет отправить, когда лимит освободится.
+		keyboard := confirmationKeyboard(lang)
+		ch.commandHandler.sendOrEdit(chatID, 0, i18n.T(lang, "submit.rate_limited"), &keyboard)
+		return
+	case err != nil:
+		logrus.WithError(err).Error("Ошибка при отправке заявки менеджеру")
+
+		keyboard := confirmationKeyboard(lang)
+		ch.commandHandler.sendOrEdit(chatID, 0, i18n.T(lang, "submit.failed"), &keyboard)
+		return
+	}
+
+	if !lead.Delivery.Delivered {
+		ch.commandHandler.sendOrEdit(chatID, 0, i18n.T(lang, "submit.queued", lead.Number), nil)
 	} else {
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"Пожалуйста, ответьте <b>\"да\"</b> для подтверждения или <b>\"нет\"</b> для перезаполнения.")
-		msg.ParseMode = "HTML"
-		ch.commandHandler.bot.Send(msg)
+		ch.commandHandler.sendOrEdit(chatID, 0, i18n.T(lang, "submit.sent", lead.Number), nil)
+
+		logrus.WithFields(logrus.Fields{
+			"user_id":     userID,
+			"username":    userInfo.Username,
+			"lead_number": lead.Number,
+		}).Info("Заявка успешно отправлена")
 	}
+
+	ch.resetUserState(userID)
 }
 
 func (ch *ConversationHandler) resetUserState(userID int64) {
-	delete(ch.commandHandler.userStates, userID)
-	delete(ch.commandHandler.userStep, userID)
+	ch.commandHandler.resetUserState(userID)
 }
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"path/filepath"
+	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/routing"
+	"pumpkin_travel_tg_bot/services"
+	"pumpkin_travel_tg_bot/storage"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// okTelegram отвечает успехом на любой запрос к Bot API.
+type okTelegram struct{}
+
+func (okTelegram) Do(req *http.Request) (*http.Response, error) {
+	body := `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}}`
+	if filepath.Base(req.URL.Path) == "getMe" {
+		body = `{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"bot","username":"bot"}}`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+	}, nil
+}
+
+func newTestConversationHandler(t *testing.T, limits services.SubmitLimits) *ConversationHandler {
+	t.Helper()
+
+	bot, err := tgbotapi.NewBotAPIWithClient("token", tgbotapi.APIEndpoint, okTelegram{})
+	if err != nil {
+		t.Fatalf("NewBotAPIWithClient: %v", err)
+	}
+	dir := t.TempDir()
+	states, err := storage.NewFileStateStore(filepath.Join(dir, "states.json"))
+	if err != nil {
+		t.Fatalf("NewFileStateStore: %v", err)
+	}
+	settings, err := storage.NewFileSettingsStore(filepath.Join(dir, "settings.json"))
+	if err != nil {
+		t.Fatalf("NewFileSettingsStore: %v", err)
+	}
+	leads, err := storage.NewFileLeadRepository(filepath.Join(dir, "leads.json"))
+	if err != nil {
+		t.Fatalf("NewFileLeadRepository: %v", err)
+	}
+	topics, err := storage.NewFileTopicStore(filepath.Join(dir, "topics.json"))
+	if err != nil {
+		t.Fatalf("NewFileTopicStore: %v", err)
+	}
+	router, err := routing.Load("", 100, "ru")
+	if err != nil {
+		t.Fatalf("routing.Load: %v", err)
+	}
+
+	commands := NewCommandHandler(bot, states, settings, loadTestForm(t))
+	return NewConversationHandler(commands, services.NewFormService(bot, leads, router, topics, limits))
+}
+
+func TestSubmitDuplicateCopyKeepsState(t *testing.T) {
+	ch := newTestConversationHandler(t, services.SubmitLimits{DuplicateWindow: time.Hour})
+	const userID = 42
+	from := &tgbotapi.User{ID: userID}
+	request := models.TravelRequest{Destination: "Турция", Budget: "до 100 тыс", Language: "ru", CreatedAt: time.Now()}
+
+	ch.submitRequest(userID, from, &storage.UserState{Step: StepConfirmation, Request: request}, userID)
+	leads, err := ch.formService.ClientLeads(userID)
+	if err != nil || len(leads) != 1 {
+		t.Fatalf("ClientLeads = %d, %v; want one lead", len(leads), err)
+	}
+
+	// Неизмененная копия открытой заявки, как после «Повторить».
+	state := storage.UserState{Step: StepConfirmation, Request: requestCopy(leads[0].Request, "ru")}
+	ch.commandHandler.UpdateUserStep(userID, &state, StepConfirmation)
+	ch.submitRequest(userID, from, &state, userID)
+
+	if leads, _ := ch.formService.ClientLeads(userID); len(leads) != 1 {
+		t.Errorf("после дубликата заявок = %d, want 1", len(leads))
+	}
+	saved, exists := ch.commandHandler.GetUserState(userID)
+	if !exists || saved.Step != StepConfirmation || saved.Request.Destination != "Турция" {
+		t.Fatalf("анкета после отклоненного дубликата = %+v, %v; want сохраненную копию", saved, exists)
+	}
+}
+
+func TestOptionAnswersStoredInFormLanguage(t *testing.T) {
+	ch := newTestConversationHandler(t, services.SubmitLimits{})
+	form := ch.commandHandler.form
+	models.SetAnswerTranslator(form.AnswerText)
+	t.Cleanup(func() { models.SetAnswerTranslator(nil) })
+
+	const userID = 42
+	vacation, _ := form.Question("vacation_type")
+	state := storage.UserState{Step: vacation.Key, Request: models.TravelRequest{Language: "en"}}
+	ch.commandHandler.UpdateUserStep(userID, &state, state.Step)
+
+	press := func(data string) {
+		t.Helper()
+		current, _ := ch.commandHandler.GetUserState(userID)
+		query := &tgbotapi.CallbackQuery{
+			ID:      "1",
+			Data:    data,
+			Message: &tgbotapi.Message{MessageID: 10, Chat: &tgbotapi.Chat{ID: userID}},
+		}
+		ch.handleQuestionCallback(query, current, userID)
+	}
+	keyboard := optionsKeyboard(vacation, "en", nil)
+	press(*keyboard.InlineKeyboard[0][0].CallbackData) // Beach
+	press(optionsDoneCallback(vacation))
+
+	saved, _ := ch.commandHandler.GetUserState(userID)
+	if saved.Request.VacationType != "Пляжный" {
+		t.Fatalf("VacationType = %q, want %q", saved.Request.VacationType, "Пляжный")
+	}
+	beach := routing.Manager{ChatID: 1, VacationTypes: []string{"пляжный"}}
+	if !beach.Matches(&saved.Request) {
+		t.Error("правило по типу отдыха на русском не подошло заявке на английском")
+	}
+
+	// Свой ответ, совпадающий с вариантом, записывается так же, как нажатие кнопки.
+	hotel, _ := form.Question("hotel_level")
+	ch.acceptAnswer(userID, 0, saved, userID, hotel, "any level")
+	saved, _ = ch.commandHandler.GetUserState(userID)
+	if saved.Request.HotelLevel != "Любой уровень" {
+		t.Errorf("HotelLevel = %q, want %q", saved.Request.HotelLevel, "Любой уровень")
+	}
+
+	preview := saved.Request.ToClientPreview()
+	if !strings.Contains(preview, "Beach") || !strings.Contains(preview, "Any level") {
+		t.Errorf("предпросмотр для клиента не переведен:\n%s", preview)
+	}
+	card := saved.Request.ToFormattedString(models.UserInfo{ID: userID}, "ru")
+	if !strings.Contains(card, "Пляжный") || !strings.Contains(card, "Любой уровень") {
+		t.Errorf("карточка менеджера не на языке менеджера:\n%s", card)
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"html"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/storage"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	editCallbackPrefix = "edit_"
+	editMenuCallback   = "edit_menu"
+	editCancelCallback = "edit_cancel"
+	editFieldPrefix    = "edit_field:"
+)
+
+// handleEditCallback обрабатывает кнопки правки ответов на шаге подтверждения:
+// открытие списка полей, выбор поля и возврат к заявке.
+func (ch *ConversationHandler) handleEditCallback(query *tgbotapi.CallbackQuery, state *storage.UserState, userID int64) {
+	lang := state.Request.Language
+	if state.Step != StepConfirmation {
+		ch.answerCallback(query.ID, i18n.T(lang, "callback.wrong_step"))
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+
+	switch {
+	case query.Data == editMenuCallback:
+		ch.answerCallback(query.ID, "")
+		keyboard := ch.editFieldsKeyboard(state)
+		ch.commandHandler.sendOrEdit(chatID, messageID, i18n.T(lang, "edit.choose_field"), &keyboard)
+
+	case query.Data == editCancelCallback:
+		ch.answerCallback(query.ID, "")
+		ch.showConfirmation(chatID, messageID, "", state, userID)
+
+	case strings.HasPrefix(query.Data, editFieldPrefix):
+		question, ok := ch.commandHandler.form.Question(strings.TrimPrefix(query.Data, editFieldPrefix))
+		if !ok {
+			ch.answerCallback(query.ID, i18n.T(lang, "callback.wrong_step"))
+			return
+		}
+
+		ch.answerCallback(query.ID, "")
+		state.Editing = true
+		ch.commandHandler.UpdateUserStep(userID, state, question.Key)
+
+		prefix := ""
+		if answer := state.Request.Field(question.Key); answer != "" {
+			prefix = i18n.T(lang, "edit.current", html.EscapeString(question.AnswerText(lang, answer))) + "\n\n"
+		}
+		ch.commandHandler.askQuestion(chatID, messageID, prefix, question, state)
+
+		logrus.WithFields(logrus.Fields{
+			"user_id": userID,
+			"step":    question.Key,
+		}).Debug("Пользователь редактирует ответ")
+
+	default:
+		ch.answerCallback(query.ID, i18n.T(lang, "callback.wrong_step"))
+	}
+}
+
+func (ch *ConversationHandler) editFieldsKeyboard(state *storage.UserState) tgbotapi.InlineKeyboardMarkup {
+	lang := state.Request.Language
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, question := range ch.commandHandler.form.Applicable(&state.Request) {
+		label := question.LabelText(lang)
+		if label == "" {
+			label = question.Key
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, editFieldPrefix+question.Key),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.back_to_request"), editCancelCallback),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/storage"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const languageCallbackPrefix = "lang_"
+
+// HandleLanguage показывает кнопки выбора языка бота.
+func (ch *CommandHandler) HandleLanguage(update tgbotapi.Update) {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, lang := range i18n.Languages() {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "language.name"), languageCallbackPrefix+lang))
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(row)
+
+	ch.sendOrEdit(update.Message.Chat.ID, 0, i18n.T(ch.Language(update.Message.From), "language.choose"), &keyboard)
+}
+
+// handleLanguageCallback сохраняет выбранный язык. Незаконченная анкета
+// продолжается на новом языке: текущий вопрос задается заново.
+func (ch *ConversationHandler) handleLanguageCallback(query *tgbotapi.CallbackQuery) {
+	userID := query.From.ID
+	lang := strings.TrimPrefix(query.Data, languageCallbackPrefix)
+	if !i18n.IsSupported(lang) {
+		ch.answerCallback(query.ID, i18n.T(ch.commandHandler.Language(query.From), "callback.unknown_action"))
+		return
+	}
+
+	if err := ch.commandHandler.settings.Put(userID, storage.UserSettings{Language: lang}); err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Error("Ошибка сохранения настроек пользователя")
+	}
+	ch.answerCallback(query.ID, "")
+	ch.commandHandler.sendOrEdit(query.Message.Chat.ID, query.Message.MessageID, i18n.T(lang, "language.changed"), nil)
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":  userID,
+		"language": lang,
+	}).Info("Пользователь выбрал язык бота")
+
+	state, exists := ch.commandHandler.GetUserState(userID)
+	if !exists {
+		return
+	}
+	state.Request.Language = lang
+	ch.commandHandler.UpdateUserStep(userID, state, state.Step)
+
+	if question, ok := ch.commandHandler.form.Question(state.Step); ok {
+		ch.commandHandler.askQuestion(query.Message.Chat.ID, 0, "", question, state)
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"html"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/services"
+	"pumpkin_travel_tg_bot/utils"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// maxListedLeads — сколько заявок показывает /leads.
+const maxListedLeads = 20
+
+// ManagerHandler обрабатывает действия менеджеров с карточками заявок.
+type ManagerHandler struct {
+	bot         *tgbotapi.BotAPI
+	formService *services.FormService
+}
+
+func NewManagerHandler(bot *tgbotapi.BotAPI, formService *services.FormService) *ManagerHandler {
+	return &ManagerHandler{
+		bot:         bot,
+		formService: formService,
+	}
+}
+
+// HandleCallback меняет статус заявки по кнопке на ее карточке.
+func (mh *ManagerHandler) HandleCallback(update tgbotapi.Update) {
+	query := update.CallbackQuery
+	if query.Message == nil {
+		mh.answerCallback(query.ID, i18n.T(mh.formService.ManagerLanguage(0), "manager.card_unavailable"))
+		return
+	}
+	lang := mh.formService.ManagerLanguage(query.Message.Chat.ID)
+
+	number, status, ok := services.ParseLeadCallback(query.Data)
+	if !ok {
+		mh.answerCallback(query.ID, i18n.T(lang, "callback.unknown_action"))
+		return
+	}
+
+	manager := models.UserInfo{
+		ID:        query.From.ID,
+		FirstName: query.From.FirstName,
+		LastName:  query.From.LastName,
+		Username:  query.From.UserName,
+	}
+
+	lead, err := mh.formService.ChangeLeadStatus(number, status, query.Message.Chat.ID, manager)
+	switch {
+	case errors.Is(err, services.ErrLeadNotFound):
+		mh.answerCallback(query.ID, i18n.T(lang, "manager.lead_not_found"))
+	case errors.Is(err, services.ErrLeadStatusChange):
+		mh.answerCallback(query.ID, i18n.T(lang, "manager.already_status", lead.Status.Title(lang)))
+	case err != nil:
+		logrus.WithError(err).WithField("lead_number", number).Error("Ошибка смены статуса заявки")
+		mh.answerCallback(query.ID, i18n.T(lang, "manager.status_failed"))
+	case status == models.LeadStatusContacted && lead.UserInfo.Username != "":
+		mh.answerCallback(query.ID, i18n.T(lang, "manager.write_client", lead.UserInfo.Username))
+	default:
+		mh.answerCallback(query.ID, lead.Status.Title(lang))
+	}
+}
+
+// HandleMessage пересылает клиенту ответ менеджера в переписке по заявке.
+// Возвращает false, если сообщение не является ответом на карточку заявки
+// или на сообщение клиента, — такие сообщения бот не обрабатывает.
+func (mh *ManagerHandler) HandleMessage(update tgbotapi.Update) bool {
+	message := update.Message
+	lead, err := mh.formService.RelayToClient(message)
+	switch {
+	case errors.Is(err, services.ErrLeadNotFound):
+		return false
+	case err != nil && lead == nil:
+		logrus.WithError(err).Error("Ошибка поиска заявки для пересылки сообщения клиенту")
+	case err != nil:
+		logrus.WithError(err).WithField("lead_number", lead.Number).Error("Ошибка пересылки сообщения клиенту")
+
+		lang := mh.formService.ManagerLanguage(message.Chat.ID)
+		reply := tgbotapi.NewMessage(message.Chat.ID, i18n.T(lang, "manager.relay_failed", lead.Number))
+		reply.ReplyToMessageID = message.MessageID
+		mh.bot.Send(reply)
+	}
+	return true
+}
+
+// HandleLeads выводит незавершенные заявки чата менеджеров от больших бюджетов
+// к меньшим: /leads [бюджет], например «/leads 100-200 тыс» или «/leads до 3000 $».
+func (mh *ManagerHandler) HandleLeads(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	lang := mh.formService.ManagerLanguage(chatID)
+
+	var budget *models.BudgetRange
+	if args := strings.TrimSpace(update.Message.CommandArguments()); args != "" {
+		if budget = utils.ParseBudget(args); budget == nil {
+			mh.reply(chatID, i18n.T(lang, "manager.leads_usage"))
+			return
+		}
+	}
+
+	leads, err := mh.formService.ManagerLeads(chatID, budget)
+	if err != nil {
+		logrus.WithError(err).WithField("chat_id", chatID).Error("Ошибка чтения заявок для менеджера")
+		mh.reply(chatID, i18n.T(lang, "manager.leads_failed"))
+		return
+	}
+	if len(leads) == 0 {
+		mh.reply(chatID, i18n.T(lang, "manager.leads_empty"))
+		return
+	}
+
+	var builder strings.Builder
+	builder.WriteString(i18n.T(lang, "manager.leads_title", len(leads)) + "\n\n")
+	for i, lead := range leads {
+		if i == maxListedLeads {
+			builder.WriteString(i18n.T(lang, "manager.leads_more", maxListedLeads))
+			break
+		}
+
+		budgetText := lead.Request.Budget
+		if budgetText == "" {
+			budgetText = i18n.T(lang, "card.not_specified")
+		}
+		builder.WriteString(i18n.T(lang, "manager.leads_item",
+			lead.Number,
+			html.EscapeString(leadDestination(&lead, lang)),
+			html.EscapeString(budgetText),
+			lead.Status.Title(lang)) + "\n\n")
+	}
+	mh.reply(chatID, builder.String())
+}
+
+func (mh *ManagerHandler) reply(chatID int64, text string) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if _, err := mh.bot.Send(msg); err != nil {
+		logrus.WithError(err).WithField("chat_id", chatID).Error("Ошибка отправки сообщения в чат менеджеров")
+	}
+}
+
+func (mh *ManagerHandler) answerCallback(callbackID, text string) {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	if _, err := mh.bot.Request(callback); err != nil {
+		logrus.Error("Ошибка отправки callback:", err)
+	}
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/services"
+	"pumpkin_travel_tg_bot/storage"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	myRequestsCallbackPrefix = "my_"
+	myRequestsListCallback   = "my_list"
+	myRequestShowPrefix      = "my_show_"
+	myRequestCancelPrefix    = "my_cancel_"
+	myRequestCancelOKPrefix  = "my_cancelok_"
+	myRequestCopyPrefix      = "my_copy_"
+
+	// maxListedRequests — сколько последних заявок показывает /myrequests.
+	maxListedRequests = 10
+)
+
+// HandleMyRequests показывает клиенту его заявки со статусами.
+func (ch *ConversationHandler) HandleMyRequests(update tgbotapi.Update) {
+	ch.showMyRequests(update.Message.Chat.ID, 0, update.Message.From.ID, ch.commandHandler.Language(update.Message.From))
+}
+
+func (ch *ConversationHandler) showMyRequests(chatID int64, messageID int, userID int64, lang string) {
+	leads, err := ch.formService.ClientLeads(userID)
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Error("Ошибка чтения заявок клиента")
+		ch.commandHandler.sendOrEdit(chatID, messageID, i18n.T(lang, "my.load_failed"), nil)
+		return
+	}
+
+	if len(leads) == 0 {
+		ch.commandHandler.sendOrEdit(chatID, messageID, i18n.T(lang, "my.no_requests"), nil)
+		return
+	}
+	if len(leads) > maxListedRequests {
+		leads = leads[:maxListedRequests]
+	}
+
+	var builder strings.Builder
+	builder.WriteString(i18n.T(lang, "my.title") + "\n\n")
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, lead := range leads {
+		builder.WriteString(i18n.T(lang, "my.item",
+			lead.Number,
+			lead.CreatedAt.Format(i18n.T(lang, "format.date")),
+			html.EscapeString(leadDestination(&lead, lang)),
+			lead.Status.Title(lang)) + "\n\n")
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				i18n.T(lang, "my.item_button", lead.Number, leadDestination(&lead, lang)),
+				fmt.Sprintf("%s%d", myRequestShowPrefix, lead.Number)),
+		))
+	}
+	builder.WriteString(i18n.T(lang, "my.hint"))
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	ch.commandHandler.sendOrEdit(chatID, messageID, builder.String(), &keyboard)
+}
+
+// handleMyRequestsCallback обрабатывает кнопки списка заявок клиента:
+// просмотр, отмену и повтор заявки.
+func (ch *ConversationHandler) handleMyRequestsCallback(query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+	userID := query.From.ID
+	lang := ch.commandHandler.Language(query.From)
+
+	if query.Data == myRequestsListCallback {
+		ch.answerCallback(query.ID, "")
+		ch.showMyRequests(chatID, messageID, userID, lang)
+		return
+	}
+
+	var prefix string
+	for _, p := range []string{myRequestShowPrefix, myRequestCancelOKPrefix, myRequestCancelPrefix, myRequestCopyPrefix} {
+		if strings.HasPrefix(query.Data, p) {
+			prefix = p
+			break
+		}
+	}
+	number, err := strconv.ParseInt(strings.TrimPrefix(query.Data, prefix), 10, 64)
+	if prefix == "" || err != nil {
+		ch.answerCallback(query.ID, i18n.T(lang, "callback.unknown_action"))
+		return
+	}
+
+	lead, err := ch.formService.ClientLead(userID, number)
+	if err != nil {
+		ch.answerCallback(query.ID, i18n.T(lang, "my.not_found"))
+		return
+	}
+
+	switch prefix {
+	case myRequestShowPrefix:
+		ch.answerCallback(query.ID, "")
+		ch.showMyRequest(chatID, messageID, lead, lang)
+
+	case myRequestCancelPrefix:
+		ch.answerCallback(query.ID, "")
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.confirm_cancel"), fmt.Sprintf("%s%d", myRequestCancelOKPrefix, number)),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.no"), fmt.Sprintf("%s%d", myRequestShowPrefix, number)),
+		))
+		ch.commandHandler.sendOrEdit(chatID, messageID, i18n.T(lang, "my.cancel_confirm", number), &keyboard)
+
+	case myRequestCancelOKPrefix:
+		cancelled, err := ch.formService.CancelLead(userID, number)
+		switch {
+		case errors.Is(err, services.ErrLeadStatusChange):
+			ch.answerCallback(query.ID, i18n.T(lang, "my.already_final"))
+			ch.showMyRequest(chatID, messageID, cancelled, lang)
+		case err != nil:
+			logrus.WithError(err).WithField("lead_number", number).Error("Ошибка отмены заявки")
+			ch.answerCallback(query.ID, i18n.T(lang, "my.cancel_failed"))
+		default:
+			ch.answerCallback(query.ID, i18n.T(lang, "my.cancelled"))
+			ch.showMyRequest(chatID, messageID, cancelled, lang)
+		}
+
+	case myRequestCopyPrefix:
+		ch.answerCallback(query.ID, "")
+		ch.commandHandler.editKeyboard(chatID, messageID, nil)
+		ch.copyRequest(chatID, userID, lead, lang)
+	}
+}
+
+func (ch *ConversationHandler) showMyRequest(chatID int64, messageID int, lead *models.Lead, lang string) {
+	// Превью заявки показывается на языке, на котором ее заполняли.
+	text := i18n.T(lang, "my.details", lead.Number, lead.CreatedAt.Format(i18n.T(lang, "format.datetime")),
+		lead.Status.Title(lang), lead.Request.ToClientPreview())
+
+	var actions []tgbotapi.InlineKeyboardButton
+	if !lead.Status.IsFinal() {
+		actions = append(actions, tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.cancel_request"),
+			fmt.Sprintf("%s%d", myRequestCancelPrefix, lead.Number)))
+	}
+	actions = append(actions, tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.repeat_request"),
+		fmt.Sprintf("%s%d", myRequestCopyPrefix, lead.Number)))
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		actions,
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.to_list"), myRequestsListCallback)),
+	)
+	ch.commandHandler.sendOrEdit(chatID, messageID, text, &keyboard)
+}
+
+// copyRequest начинает новую заявку с ответами из прежней. Прошедшие даты
+// не копируются — о них клиента спросят заново, остальное он проверит
+// на шаге подтверждения.
+func (ch *ConversationHandler) copyRequest(chatID, userID int64, lead *models.Lead, lang string) {
+	state := &storage.UserState{Request: requestCopy(lead.Request, lang)}
+
+	if window := state.Request.DateWindow; window != nil && window.IsPast(state.Request.CreatedAt) {
+		state.Request.TravelDates = ""
+		state.Request.DateWindow = nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":     userID,
+		"lead_number": lead.Number,
+	}).Info("Клиент повторяет заявку")
+
+	if next := ch.commandHandler.form.NextUnanswered(&state.Request); next != nil {
+		state.Editing = true
+		ch.commandHandler.UpdateUserStep(userID, state, next.Key)
+		ch.commandHandler.askQuestion(chatID, 0, i18n.T(lang, "my.copied_question", lead.Number)+"\n\n", next, state)
+		return
+	}
+
+	ch.showConfirmation(chatID, 0, i18n.T(lang, "my.copied", lead.Number)+"\n\n", state, userID)
+}
+
+// requestCopy возвращает новую анкету с ответами прежней заявки. Копия не
+// разделяет с заявкой срезы и указатели: правка ответов не меняет сохраненную заявку.
+func requestCopy(request models.TravelRequest, lang string) models.TravelRequest {
+	copied := request.Clone()
+	copied.CreatedAt = time.Now()
+	copied.Language = lang
+	return copied
+}
+
+// leadDestination — краткое направление заявки для списка.
+func leadDestination(lead *models.Lead, lang string) string {
+	switch {
+	case len(lead.Request.Destinations) > 0:
+		return strings.Join(lead.Request.Destinations, ", ")
+	case lead.Request.Destination != "":
+		return lead.Request.Destination
+	}
+	return i18n.T(lang, "my.no_destination")
+}
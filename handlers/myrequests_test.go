@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/questionnaire"
+	"pumpkin_travel_tg_bot/storage"
+	"testing"
+)
+
+func TestRequestCopyDoesNotChangeOriginalLead(t *testing.T) {
+	form, err := questionnaire.Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	ch := &CommandHandler{form: form}
+
+	lead := models.Lead{
+		Number: 1,
+		Request: models.TravelRequest{
+			Travelers:    "2 взрослых и 2 детей",
+			ChildAge:     "3 года, 7 лет",
+			Destinations: []string{"Турция"},
+			Party:        &models.TravelParty{Adults: 2, Children: 2, ChildAges: []int{3, 7}},
+			ExtraAnswers: []models.ExtraAnswer{{Key: "pets", Label: "Питомцы", Value: "нет"}},
+		},
+	}
+
+	// Клиент скопировал заявку и заново отвечает про возраст детей.
+	state := &storage.UserState{Request: requestCopy(lead.Request, i18n.EN)}
+	ch.resetAnswerDraft(state, "child_age")
+	collectChildAge(&state.Request, "5")
+	collectChildAge(&state.Request, "10")
+	state.Request.Destinations[0] = "Египет"
+	state.Request.SetField("pets", "Питомцы", "кошка")
+
+	original := lead.Request
+	if ages := original.Party.ChildAges; len(ages) != 2 || ages[0] != 3 || ages[1] != 7 {
+		t.Errorf("original ChildAges = %v, want [3 7]", ages)
+	}
+	if original.Destinations[0] != "Турция" {
+		t.Errorf("original Destinations = %v, want [Турция]", original.Destinations)
+	}
+	if value := original.Field("pets"); value != "нет" {
+		t.Errorf("original pets = %q, want %q", value, "нет")
+	}
+	if original.Language != "" {
+		t.Errorf("original Language = %q, want empty", original.Language)
+	}
+
+	if ages := state.Request.Party.ChildAges; len(ages) != 2 || ages[0] != 5 || ages[1] != 10 {
+		t.Errorf("copy ChildAges = %v, want [5 10]", ages)
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/questionnaire"
+	"pumpkin_travel_tg_bot/utils"
+)
+
+// answerParsers разбирают ответы на вопросы анкеты в структурированные поля
+// заявки. Исходный текст ответа сохраняется как есть.
+var answerParsers = map[string]func(request *models.TravelRequest, answer string){
+	"destination": func(request *models.TravelRequest, answer string) {
+		request.Destinations, request.DestinationUndecided = utils.ParseDestinations(answer)
+	},
+	"budget": func(request *models.TravelRequest, answer string) {
+		request.BudgetRange = utils.ParseBudget(answer)
+	},
+	"departure_city": func(request *models.TravelRequest, answer string) {
+		request.Departure = utils.ParseDepartureCity(answer)
+	},
+	"travel_dates": func(request *models.TravelRequest, answer string) {
+		// Несуществующие даты отклоняет валидатор, поэтому ошибку здесь можно не проверять.
+		request.DateWindow, _ = utils.ParseTravelDates(answer, questionnaire.RequestTime(request))
+	},
+	"duration": func(request *models.TravelRequest, answer string) {
+		request.Nights = utils.ParseDuration(answer)
+	},
+	"travelers": func(request *models.TravelRequest, answer string) {
+		party := utils.ParseTravelers(answer)
+		// Возраст детей остается в силе, только если их количество не изменилось.
+		if party != nil && request.Party != nil && party.Children == request.Party.Children {
+			party.ChildAges = request.Party.ChildAges
+		} else {
+			request.ChildAge = ""
+		}
+		request.Party = party
+	},
+}
+
+func parseAnswer(request *models.TravelRequest, key, answer string) {
+	if parse, ok := answerParsers[key]; ok {
+		parse(request, answer)
+	}
+}
+
+// collectChildAge добавляет возраст очередного ребенка. Возвращает текст ответа
+// про всех детей на языке клиента и true, когда возраст известен для каждого ребенка.
+func collectChildAge(request *models.TravelRequest, answer string) (string, bool) {
+	party := request.Party
+	age, ok := utils.ParseChildAge(answer)
+	if party == nil || !ok {
+		return answer, true
+	}
+
+	party.ChildAges = append(party.ChildAges, age)
+	if len(party.ChildAges) < party.Children {
+		return "", false
+	}
+	return party.ChildAgesText(request.Language), true
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"fmt"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/questionnaire"
+	"pumpkin_travel_tg_bot/storage"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// Кнопки вариантов называют свой вопрос, чтобы нажатие на клавиатуре
+	// прошлого вопроса не записалось ответом на текущий: opt_<ряд>_<столбец>_<ключ>.
+	optionCallbackFormat      = "opt_%d_%d_%s"
+	optionsDoneCallbackPrefix = "opt_done_"
+
+	counterCallbackPrefix = "cnt_"
+	adultsIncCallback     = "cnt_adults_inc"
+	adultsDecCallback     = "cnt_adults_dec"
+	childrenIncCallback   = "cnt_children_inc"
+	childrenDecCallback   = "cnt_children_dec"
+	countersDoneCallback  = "cnt_done"
+	noopCallback          = "noop"
+
+	defaultAdults = 2
+	minAdults     = 1
+	maxAdults     = 10
+	maxChildren   = 6
+)
+
+// askQuestion отправляет вопрос анкеты на языке клиента с кнопками, если они
+// предусмотрены его типом. Если передан messageID, вопрос заменяет текст этого сообщения.
+func (ch *CommandHandler) askQuestion(chatID int64, messageID int, prefix string, q *questionnaire.Question, state *storage.UserState) {
+	lang := state.Request.Language
+	keyboard := questionKeyboard(q, state)
+	if previous, ok := keepableAnswer(q, state); ok {
+		prefix += keepPrefix(lang, q.AnswerText(lang, previous))
+		keyboard = keepButtons(lang, keyboard)
+	}
+
+	if party := state.Request.Party; q.Type == questionnaire.InputChildAges && party != nil && party.Children > 1 {
+		prefix += i18n.T(lang, "question.child_of", len(party.ChildAges)+1, party.Children) + "\n"
+	}
+
+	ch.sendOrEdit(chatID, messageID, prefix+q.PromptText(lang), keyboard)
+}
+
+// sendOrEdit отправляет HTML-сообщение или, если передан messageID,
+// редактирует уже отправленное.
+func (ch *CommandHandler) sendOrEdit(chatID int64, messageID int, text string, keyboard *tgbotapi.InlineKeyboardMarkup) {
+	if messageID != 0 {
+		editMsg := tgbotapi.NewEditMessageText(chatID, messageID, text)
+		editMsg.ParseMode = "HTML"
+		editMsg.ReplyMarkup = keyboard
+		ch.bot.Send(editMsg)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	ch.bot.Send(msg)
+}
+
+// editKeyboard заменяет кнопки сообщения, не трогая его текст.
+func (ch *CommandHandler) editKeyboard(chatID int64, messageID int, keyboard *tgbotapi.InlineKeyboardMarkup) {
+	markup := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	if keyboard != nil {
+		markup = *keyboard
+	}
+	ch.bot.Send(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, markup))
+}
+
+func questionKeyboard(q *questionnaire.Question, state *storage.UserState) *tgbotapi.InlineKeyboardMarkup {
+	var keyboard tgbotapi.InlineKeyboardMarkup
+	lang := state.Request.Language
+
+	switch q.Type {
+	case questionnaire.InputChoice:
+		keyboard = optionsKeyboard(q, lang, nil)
+	case questionnaire.InputMultiChoice:
+		keyboard = optionsKeyboard(q, lang, state.Selected)
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.done"), optionsDoneCallback(q)),
+		))
+	case questionnaire.InputTravelers:
+		keyboard = travelersKeyboard(state)
+	default:
+		return nil
+	}
+
+	return &keyboard
+}
+
+// optionsKeyboard строит кнопки вариантов ответа на языке lang; отмеченные
+// варианты, записанные на языке анкеты, помечаются галочкой.
+func optionsKeyboard(q *questionnaire.Question, lang string, selected []string) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(q.Options))
+	for i, options := range q.OptionsText(lang) {
+		row := make([]tgbotapi.InlineKeyboardButton, 0, len(options))
+		for j, option := range options {
+			label := option
+			if containsString(selected, q.Options[i][j]) {
+				label = "✅ " + option
+			}
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf(optionCallbackFormat, i, j, q.Key)))
+		}
+		rows = append(rows, row)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func travelersKeyboard(state *storage.UserState) tgbotapi.InlineKeyboardMarkup {
+	lang := state.Request.Language
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "counters.adults", state.Adults), noopCallback),
+			tgbotapi.NewInlineKeyboardButtonData("➖", adultsDecCallback),
+			tgbotapi.NewInlineKeyboardButtonData("➕", adultsIncCallback),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "counters.children", state.Children), noopCallback),
+			tgbotapi.NewInlineKeyboardButtonData("➖", childrenDecCallback),
+			tgbotapi.NewInlineKeyboardButtonData("➕", childrenIncCallback),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.done"), countersDoneCallback),
+		),
+	)
+}
+
+func optionsDoneCallback(q *questionnaire.Question) string {
+	return optionsDoneCallbackPrefix + q.Key
+}
+
+// selectedOption возвращает вариант ответа на языке анкеты, выбранный нажатием
+// кнопки. Кнопки с клавиатуры другого вопроса не принимаются.
+func selectedOption(q *questionnaire.Question, data string) (string, bool) {
+	var row, col int
+	var key string
+	if _, err := fmt.Sscanf(data, optionCallbackFormat, &row, &col, &key); err != nil || key != q.Key {
+		return "", false
+	}
+	return q.Option(row, col)
+}
+
+// toggleOption отмечает вариант или снимает отметку. Варианты хранятся
+// на языке анкеты в порядке их следования в ней.
+func toggleOption(q *questionnaire.Question, selected []string, option string) []string {
+	if containsString(selected, option) {
+		var result []string
+		for _, s := range selected {
+			if s != option {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+
+	var result []string
+	for _, row := range q.Options {
+		for _, o := range row {
+			if o == option || containsString(selected, o) {
+				result = append(result, o)
+			}
+		}
+	}
+	return result
+}
+
+// formatTravelers описывает состав туристов так же, как его пишут клиенты:
+// «2 взрослых + 1 ребёнок».
+func formatTravelers(lang string, adults, children int) string {
+	text := fmt.Sprintf("%d %s", adults, i18n.Plural(lang, "adults", adults))
+	if children > 0 {
+		text += fmt.Sprintf(" + %d %s", children, i18n.Plural(lang, "children", children))
+	}
+	return text
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func joinSelected(selected []string) string {
+	return strings.Join(selected, questionnaire.MultiChoiceSeparator)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/questionnaire"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func loadTestForm(t *testing.T) *questionnaire.Form {
+	t.Helper()
+
+	form, err := questionnaire.Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return form
+}
+
+func TestSelectedOptionRejectsStaleKeyboard(t *testing.T) {
+	form := loadTestForm(t)
+	hotel, _ := form.Question("hotel_level")
+	meal, _ := form.Question("meal_plan")
+	if hotel == nil || meal == nil {
+		t.Fatal("form has no hotel_level or meal_plan question")
+	}
+
+	keyboard := optionsKeyboard(hotel, i18n.RU, nil)
+	button := keyboard.InlineKeyboard[0][0]
+	data := *button.CallbackData
+
+	// Кнопка со старой клавиатуры вопроса об отеле, нажатая на шаге питания.
+	if option, ok := selectedOption(meal, data); ok {
+		t.Errorf("selectedOption(meal_plan, %q) = %q, want rejection", data, option)
+	}
+
+	option, ok := selectedOption(hotel, data)
+	if !ok || option != button.Text {
+		t.Errorf("selectedOption(hotel_level, %q) = %q, %v; want %q", data, option, ok, button.Text)
+	}
+}
+
+func TestSelectedOptionInFormLanguage(t *testing.T) {
+	form := loadTestForm(t)
+	meal, _ := form.Question("meal_plan")
+
+	// Кнопки подписаны на языке клиента, а в заявку попадает вариант на языке анкеты.
+	keyboard := optionsKeyboard(meal, i18n.EN, nil)
+	for i, row := range keyboard.InlineKeyboard {
+		for j, button := range row {
+			option, ok := selectedOption(meal, *button.CallbackData)
+			if !ok || option != meal.Options[i][j] {
+				t.Errorf("selectedOption(%q) = %q, %v; want %q", *button.CallbackData, option, ok, meal.Options[i][j])
+			}
+			if text := meal.AnswerText(i18n.EN, option); text != button.Text {
+				t.Errorf("AnswerText(en, %q) = %q, want %q", option, text, button.Text)
+			}
+		}
+	}
+
+	for _, data := range []string{"opt_0_0", "opt_9_9_meal_plan", "opt_done_meal_plan", "garbage"} {
+		if option, ok := selectedOption(meal, data); ok {
+			t.Errorf("selectedOption(%q) = %q, want rejection", data, option)
+		}
+	}
+}
+
+func TestToggleOptionMarksButtonsInClientLanguage(t *testing.T) {
+	form := loadTestForm(t)
+	vacation, _ := form.Question("vacation_type")
+
+	keyboard := optionsKeyboard(vacation, i18n.EN, nil)
+	var selected []string
+	for _, button := range []tgbotapi.InlineKeyboardButton{keyboard.InlineKeyboard[1][0], keyboard.InlineKeyboard[0][0]} {
+		option, _ := selectedOption(vacation, *button.CallbackData)
+		selected = toggleOption(vacation, selected, option)
+	}
+
+	if got, want := joinSelected(selected), "Пляжный + Активный"; got != want {
+		t.Errorf("joinSelected = %q, want %q", got, want)
+	}
+	if got, want := vacation.AnswerText(i18n.EN, joinSelected(selected)), "Beach + Active"; got != want {
+		t.Errorf("AnswerText(en) = %q, want %q", got, want)
+	}
+
+	keyboard = optionsKeyboard(vacation, i18n.EN, selected)
+	if got := keyboard.InlineKeyboard[0][0].Text; got != "✅ Beach" {
+		t.Errorf("selected button = %q, want %q", got, "✅ Beach")
+	}
+	if got := keyboard.InlineKeyboard[0][1].Text; got != "Sightseeing" {
+		t.Errorf("unselected button = %q, want %q", got, "Sightseeing")
+	}
+}
+
+func TestOptionsDoneCallbackNamesQuestion(t *testing.T) {
+	form := loadTestForm(t)
+	vacation, _ := form.Question("vacation_type")
+	hotel, _ := form.Question("hotel_level")
+
+	if optionsDoneCallback(vacation) == optionsDoneCallback(hotel) {
+		t.Errorf("optionsDoneCallback is the same for different questions: %q", optionsDoneCallback(vacation))
+	}
+}
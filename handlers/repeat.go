@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"html"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/questionnaire"
+	"pumpkin_travel_tg_bot/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	keepCallbackPrefix = "keep_"
+	keepOneCallback    = "keep_one"
+	keepRestCallback   = "keep_rest"
+)
+
+// HandleRepeat начинает новую заявку по образцу последней отправленной:
+// на каждый вопрос можно оставить прошлый ответ одной кнопкой.
+func (ch *ConversationHandler) HandleRepeat(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	userID := update.Message.From.ID
+	lang := ch.commandHandler.Language(update.Message.From)
+
+	leads, err := ch.formService.ClientLeads(userID)
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Error("Ошибка чтения заявок клиента")
+		ch.commandHandler.sendOrEdit(chatID, 0, i18n.T(lang, "repeat.load_failed"), nil)
+		return
+	}
+	if len(leads) == 0 {
+		ch.commandHandler.sendOrEdit(chatID, 0, i18n.T(lang, "repeat.no_requests"), nil)
+		return
+	}
+
+	last := leads[0]
+	previous := last.Request.Clone()
+	state := &storage.UserState{
+		Request:  requestCopy(last.Request, lang),
+		Previous: &previous,
+	}
+
+	first := ch.commandHandler.form.First()
+	ch.commandHandler.UpdateUserStep(userID, state, first.Key)
+	ch.commandHandler.askQuestion(chatID, 0, i18n.T(lang, "repeat.intro", last.Number)+"\n\n", first, state)
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":     userID,
+		"lead_number": last.Number,
+	}).Info("Клиент начал заявку по образцу прошлой")
+}
+
+// handleKeepCallback оставляет прошлый ответ на текущий вопрос или, по кнопке
+// «Остальное без изменений», на все следующие вопросы, пока ответы подходят.
+func (ch *ConversationHandler) handleKeepCallback(query *tgbotapi.CallbackQuery, state *storage.UserState, userID int64) {
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+	lang := state.Request.Language
+
+	question, ok := ch.commandHandler.form.Question(state.Step)
+	if !ok {
+		ch.answerCallback(query.ID, i18n.T(lang, "callback.wrong_step"))
+		return
+	}
+	if _, ok := keepableAnswer(question, state); !ok {
+		ch.answerCallback(query.ID, i18n.T(lang, "keep.not_applicable"))
+		return
+	}
+	ch.answerCallback(query.ID, "")
+
+	next := question
+	for {
+		keepAnswer(next, state)
+		state.History = append(state.History, next.Key)
+		next = ch.commandHandler.form.Next(next.Key, &state.Request)
+
+		if query.Data != keepRestCallback || next == nil {
+			break
+		}
+		if _, ok := keepableAnswer(next, state); !ok {
+			break
+		}
+	}
+
+	if next == nil {
+		ch.showConfirmation(chatID, messageID, "", state, userID)
+		return
+	}
+
+	ch.commandHandler.UpdateUserStep(userID, state, next.Key)
+	ch.commandHandler.askQuestion(chatID, messageID, "", next, state)
+}
+
+// keepableAnswer возвращает прошлый ответ на вопрос, если его можно оставить:
+// он есть и по-прежнему проходит проверку с учетом новых ответов.
+func keepableAnswer(q *questionnaire.Question, state *storage.UserState) (string, bool) {
+	if state.Previous == nil || state.Editing {
+		return "", false
+	}
+
+	answer := state.Previous.Field(q.Key)
+	if answer == "" {
+		return "", false
+	}
+
+	if q.Type == questionnaire.InputChildAges {
+		// Возраст детей подходит, только если их столько же и ввод еще не начат.
+		previous, current := state.Previous.Party, state.Request.Party
+		if previous == nil || current == nil || previous.Children != current.Children ||
+			len(previous.ChildAges) != previous.Children || len(current.ChildAges) > 0 {
+			return "", false
+		}
+	}
+
+	if err := q.Validate(answer, &state.Request); err != nil {
+		return "", false
+	}
+	return answer, true
+}
+
+// keepAnswer записывает прошлый ответ на вопрос как новый.
+func keepAnswer(q *questionnaire.Question, state *storage.UserState) {
+	answer := state.Previous.Field(q.Key)
+	state.Request.SetField(q.Key, q.Label, answer)
+
+	if q.Type == questionnaire.InputChildAges {
+		state.Request.Party.ChildAges = append([]int(nil), state.Previous.Party.ChildAges...)
+		return
+	}
+	parseAnswer(&state.Request, q.Key, answer)
+}
+
+// keepPrefix и keepButtons дополняют вопрос прошлым ответом и кнопками «Оставить».
+func keepPrefix(lang, answer string) string {
+	return i18n.T(lang, "keep.previous", html.EscapeString(answer)) + "\n\n"
+}
+
+func keepButtons(lang string, keyboard *tgbotapi.InlineKeyboardMarkup) *tgbotapi.InlineKeyboardMarkup {
+	if keyboard == nil {
+		keyboard = &tgbotapi.InlineKeyboardMarkup{}
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.keep_one"), keepOneCallback),
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.keep_rest"), keepRestCallback),
+	))
+	return keyboard
+}
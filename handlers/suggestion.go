@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/storage"
+	"pumpkin_travel_tg_bot/utils"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	suggestionCallbackPrefix = "sug_"
+	suggestionKeepCallback   = "sug_keep"
+)
+
+// answerSuggesters предлагают исправления ответов с опечатками.
+// Пустой результат означает, что ответ принимается как есть.
+var answerSuggesters = map[string]func(answer string) []string{
+	"destination": func(answer string) []string {
+		if corrected, ok := utils.SuggestDestinations(answer); ok {
+			return []string{corrected}
+		}
+		return nil
+	},
+	"departure_city": utils.SuggestDepartureCities,
+}
+
+func suggestAnswer(key, answer string) []string {
+	if suggest, ok := answerSuggesters[key]; ok {
+		return suggest(answer)
+	}
+	return nil
+}
+
+// askSuggestion предлагает клиенту исправленные варианты ответа кнопками.
+func (ch *ConversationHandler) askSuggestion(chatID int64, state *storage.UserState, userID int64, answer string, options []string) {
+	state.Suggestion = &storage.AnswerSuggestion{Answer: answer, Options: options}
+	ch.commandHandler.UpdateUserStep(userID, state, state.Step)
+
+	lang := state.Request.Language
+	text := i18n.T(lang, "suggestion.single", html.EscapeString(options[0]))
+	if len(options) > 1 {
+		text = i18n.T(lang, "suggestion.multiple")
+	}
+	keyboard := suggestionKeyboard(lang, state.Suggestion)
+	ch.commandHandler.sendOrEdit(chatID, 0, text, &keyboard)
+}
+
+// handleSuggestionCallback принимает выбранный вариант исправления или исходный ответ.
+func (ch *ConversationHandler) handleSuggestionCallback(query *tgbotapi.CallbackQuery, state *storage.UserState, userID int64) {
+	question, ok := ch.commandHandler.form.Question(state.Step)
+	suggestion := state.Suggestion
+	if !ok || suggestion == nil {
+		ch.answerCallback(query.ID, i18n.T(state.Request.Language, "callback.wrong_step"))
+		return
+	}
+
+	answer := suggestion.Answer
+	if query.Data != suggestionKeepCallback {
+		index, err := strconv.Atoi(strings.TrimPrefix(query.Data, suggestionCallbackPrefix))
+		if err != nil || index < 0 || index >= len(suggestion.Options) {
+			ch.answerCallback(query.ID, i18n.T(state.Request.Language, "callback.wrong_step"))
+			return
+		}
+		answer = suggestion.Options[index]
+	}
+
+	ch.answerCallback(query.ID, "")
+	state.Suggestion = nil
+	ch.acceptAnswer(query.Message.Chat.ID, query.Message.MessageID, state, userID, question, answer)
+}
+
+func suggestionKeyboard(lang string, suggestion *storage.AnswerSuggestion) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, option := range suggestion.Options {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ "+option, fmt.Sprintf("%s%d", suggestionCallbackPrefix, i)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.keep_as_written"), suggestionKeepCallback),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,153 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+const (
+	RU = "ru"
+	EN = "en"
+
+	// Default — язык бота по умолчанию: на нем написана анкета, и на него
+	// переводятся тексты, которых нет в выбранном языке.
+	Default = RU
+)
+
+// locale — тексты бота на одном языке. Messages — строки для fmt.Sprintf,
+// Plurals — формы слова для чисел в порядке, который задает pluralIndex.
+type locale struct {
+	Messages map[string]string   `json:"messages"`
+	Plurals  map[string][]string `json:"plurals"`
+}
+
+var locales = mustLoadLocales()
+
+func mustLoadLocales() map[string]locale {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("не удалось прочитать каталог переводов: %v", err))
+	}
+
+	result := make(map[string]locale, len(entries))
+	for _, entry := range entries {
+		data, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("не удалось прочитать перевод %s: %v", entry.Name(), err))
+		}
+
+		var loc locale
+		if err := json.Unmarshal(data, &loc); err != nil {
+			panic(fmt.Sprintf("некорректный перевод %s: %v", entry.Name(), err))
+		}
+		result[strings.TrimSuffix(entry.Name(), ".json")] = loc
+	}
+
+	if _, ok := result[Default]; !ok {
+		panic("нет перевода на язык по умолчанию " + Default)
+	}
+	return result
+}
+
+// IsSupported сообщает, что для языка есть перевод.
+func IsSupported(lang string) bool {
+	_, ok := locales[lang]
+	return ok
+}
+
+// Languages возвращает поддерживаемые языки: сначала язык по умолчанию,
+// затем остальные по алфавиту.
+func Languages() []string {
+	languages := make([]string, 0, len(locales))
+	for lang := range locales {
+		if lang != Default {
+			languages = append(languages, lang)
+		}
+	}
+	sort.Strings(languages)
+	return append([]string{Default}, languages...)
+}
+
+// Detect выбирает язык по language_code из Telegram. Пользователям из стран,
+// где обычно читают по-русски, отвечаем по-русски, остальным — по-английски.
+func Detect(languageCode string) string {
+	code, _, _ := strings.Cut(strings.ToLower(languageCode), "-")
+	switch {
+	case code == "":
+		return Default
+	case IsSupported(code):
+		return code
+	case russianReaders[code]:
+		return RU
+	}
+	return EN
+}
+
+var russianReaders = map[string]bool{
+	"uk": true, "be": true, "kk": true, "uz": true, "ky": true, "tg": true, "hy": true, "az": true,
+}
+
+// T возвращает текст по ключу на языке lang, подставляя args как в fmt.Sprintf.
+// Если перевода нет, используется язык по умолчанию, а если нет и его — сам ключ.
+func T(lang, key string, args ...interface{}) string {
+	text, ok := locales[lang].Messages[key]
+	if !ok {
+		if text, ok = locales[Default].Messages[key]; !ok {
+			text = key
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Plural возвращает форму слова key для числа n: «1 взрослый», «5 взрослых».
+func Plural(lang, key string, n int) string {
+	forms, ok := locales[lang].Plurals[key]
+	if !ok {
+		lang = Default
+		if forms, ok = locales[Default].Plurals[key]; !ok {
+			return key
+		}
+	}
+
+	index := pluralIndex(lang, n)
+	if index >= len(forms) {
+		index = len(forms) - 1
+	}
+	return forms[index]
+}
+
+// pluralIndex выбирает форму слова по правилам языка. В русском три формы:
+// «1 взрослый», «2 взрослых», «5 взрослых», в английском — две.
+func pluralIndex(lang string, n int) int {
+	if n < 0 {
+		n = -n
+	}
+	if lang != RU {
+		if n == 1 {
+			return 0
+		}
+		return 1
+	}
+
+	n %= 100
+	if n >= 11 && n <= 14 {
+		return 2
+	}
+	switch n % 10 {
+	case 1:
+		return 0
+	case 2, 3, 4:
+		return 1
+	}
+	return 2
+}
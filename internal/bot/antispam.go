@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"fmt"
+	"pumpkin_travel_tg_bot/config"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/services"
+	"pumpkin_travel_tg_bot/storage"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// blocked отсекает обновления заблокированных пользователей и тех, кто пишет
+// чаще лимита MESSAGE_RATE_LIMIT. Администраторов и работу менеджеров
+// в их чатах не ограничиваем.
+func (tb *TravelBot) blocked(update tgbotapi.Update) bool {
+	userID := updateUserID(update)
+	if userID == 0 || config.AppConfig.IsAdmin(userID) {
+		return false
+	}
+
+	_, banned, err := tb.bans.Get(userID)
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Error("Ошибка чтения списка блокировок")
+	}
+	if banned {
+		logrus.WithFields(logrus.Fields{
+			"event":   "banned_user",
+			"user_id": userID,
+		}).Debug("Обновление от заблокированного пользователя пропущено")
+		return true
+	}
+
+	switch {
+	case update.CallbackQuery != nil && strings.HasPrefix(update.CallbackQuery.Data, services.LeadCallbackPrefix):
+		return false
+	case update.Message != nil && tb.formService.IsManagerChat(update.Message.Chat.ID):
+		return false
+	}
+
+	allowed, first := tb.limiter.Allow(userID)
+	if allowed {
+		return false
+	}
+
+	fields := logrus.Fields{
+		"event":   "message_rate_limited",
+		"user_id": userID,
+		"limit":   config.AppConfig.MessageRateLimit,
+		"window":  config.AppConfig.MessageRateWindow.String(),
+	}
+	if !first {
+		logrus.WithFields(fields).Debug("Сообщение сверх лимита пропущено")
+		return true
+	}
+	logrus.WithFields(fields).Warn("Пользователь превысил лимит сообщений")
+
+	// Предупреждаем один раз, чтобы не отвечать на флуд флудом.
+	switch {
+	case update.CallbackQuery != nil:
+		lang := tb.commandHandler.Language(update.CallbackQuery.From)
+		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, i18n.T(lang, "rate_limited"))
+		tb.botAPI.Request(callback)
+	case update.Message != nil:
+		lang := tb.commandHandler.Language(update.Message.From)
+		tb.botAPI.Send(tgbotapi.NewMessage(update.Message.Chat.ID, i18n.T(lang, "rate_limited")))
+	}
+	return true
+}
+
+// handleBan блокирует пользователя: /ban <user_id> [причина].
+func (tb *TravelBot) handleBan(update tgbotapi.Update) {
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		tb.replyText(update, "Использование: /ban <user_id> [причина]")
+		return
+	}
+
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		tb.replyText(update, "❌ Некорректный ID пользователя: "+args[0])
+		return
+	}
+	if config.AppConfig.IsAdmin(userID) {
+		tb.replyText(update, "❌ Администратора нельзя заблокировать")
+		return
+	}
+
+	ban := storage.Ban{
+		Reason:   strings.Join(args[1:], " "),
+		BannedBy: update.Message.From.ID,
+		BannedAt: time.Now(),
+	}
+	if err := tb.bans.Put(userID, ban); err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Error("Ошибка сохранения блокировки")
+		tb.replyText(update, "❌ Не удалось сохранить блокировку")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":     "user_banned",
+		"user_id":   userID,
+		"banned_by": ban.BannedBy,
+		"reason":    ban.Reason,
+	}).Warn("Пользователь заблокирован")
+	tb.replyText(update, fmt.Sprintf("🚫 Пользователь %d заблокирован", userID))
+}
+
+// handleUnban снимает блокировку: /unban <user_id>.
+func (tb *TravelBot) handleUnban(update tgbotapi.Update) {
+	userID, err := strconv.ParseInt(strings.TrimSpace(update.Message.CommandArguments()), 10, 64)
+	if err != nil {
+		tb.replyText(update, "Использование: /unban <user_id>")
+		return
+	}
+
+	removed, err := tb.bans.Delete(userID)
+	switch {
+	case err != nil:
+		logrus.WithError(err).WithField("user_id", userID).Error("Ошибка снятия блокировки")
+		tb.replyText(update, "❌ Не удалось снять блокировку")
+	case !removed:
+		tb.replyText(update, fmt.Sprintf("Пользователь %d не заблокирован", userID))
+	default:
+		logrus.WithFields(logrus.Fields{
+			"event":       "user_unbanned",
+			"user_id":     userID,
+			"unbanned_by": update.Message.From.ID,
+		}).Info("Блокировка пользователя снята")
+		tb.replyText(update, fmt.Sprintf("✅ Блокировка пользователя %d снята", userID))
+	}
+}
+
+// handleBanList выводит заблокированных пользователей от новых блокировок к старым.
+func (tb *TravelBot) handleBanList(update tgbotapi.Update) {
+	bans, err := tb.bans.List()
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка чтения списка блокировок")
+		tb.replyText(update, "❌ Не удалось прочитать список блокировок")
+		return
+	}
+	if len(bans) == 0 {
+		tb.replyText(update, "Список блокировок пуст")
+		return
+	}
+
+	userIDs := make([]int64, 0, len(bans))
+	for userID := range bans {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Slice(userIDs, func(i, j int) bool {
+		return bans[userIDs[i]].BannedAt.After(bans[userIDs[j]].BannedAt)
+	})
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("🚫 Заблокировано пользователей: %d\n", len(bans)))
+	for _, userID := range userIDs {
+		ban := bans[userID]
+		builder.WriteString(fmt.Sprintf("\n• %d — %s, админ %d", userID, ban.BannedAt.Format("02.01.2006 15:04"), ban.BannedBy))
+		if ban.Reason != "" {
+			builder.WriteString(": " + ban.Reason)
+		}
+	}
+	tb.replyText(update, builder.String())
+}
+
+func (tb *TravelBot) replyText(update tgbotapi.Update, text string) {
+	tb.botAPI.Send(tgbotapi.NewMessage(update.Message.Chat.ID, text))
+}
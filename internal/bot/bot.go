@@ -4,19 +4,42 @@ import (
 	"fmt"
 	"pumpkin_travel_tg_bot/config"
 	"pumpkin_travel_tg_bot/handlers"
+	"pumpkin_travel_tg_bot/i18n"
 	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/questionnaire"
+	"pumpkin_travel_tg_bot/routing"
 	"pumpkin_travel_tg_bot/services"
+	"pumpkin_travel_tg_bot/storage"
+	"pumpkin_travel_tg_bot/utils"
+	"strings"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/sirupsen/logrus"
 )
 
+const updateQueueSize = 100
+
+// adminCommands — служебные команды, доступные только администраторам из ADMIN_IDS.
+// Остальным пользователям бот отвечает на них как на неизвестные. /myid открыт
+// всем: по нему будущий администратор или менеджер узнает ID для настроек.
+var adminCommands = map[string]bool{
+	"test":    true,
+	"config":  true,
+	"ban":     true,
+	"unban":   true,
+	"banlist": true,
+}
+
 type TravelBot struct {
 	botAPI         *tgbotapi.BotAPI
 	commandHandler *handlers.CommandHandler
 	convHandler    *handlers.ConversationHandler
+	managerHandler *handlers.ManagerHandler
 	formService    *services.FormService
+	reminders      *services.ReminderService
+	bans           storage.BanStore
+	limiter        *services.RateLimiter
 }
 
 func NewTravelBot() (*TravelBot, error) {
@@ -34,51 +57,140 @@ func NewTravelBot() (*TravelBot, error) {
 	logrus.Infof("Авторизован как %s", botAPI.Self.UserName)
 	logrus.Infof("ID бота: %d", botAPI.Self.ID)
 
-	formService := services.NewFormService(botAPI)
-	commandHandler := handlers.NewCommandHandler(botAPI)
+	stateStore, err := storage.NewFileStateStore(config.AppConfig.StateFile)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия хранилища состояний: %w", err)
+	}
+
+	leadRepository, err := storage.NewFileLeadRepository(config.AppConfig.LeadsFile)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия базы заявок: %w", err)
+	}
+
+	settingsStore, err := storage.NewFileSettingsStore(config.AppConfig.SettingsFile)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия хранилища настроек: %w", err)
+	}
+
+	banStore, err := storage.NewFileBanStore(config.AppConfig.BansFile)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия списка блокировок: %w", err)
+	}
+
+	form, err := questionnaire.Load(config.AppConfig.FormFile)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки анкеты: %w", err)
+	}
+	models.SetAnswerTranslator(form.AnswerText)
+
+	router, err := routing.Load(config.AppConfig.ManagersFile, config.AppConfig.ManagerChatID, config.AppConfig.ManagerLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки списка менеджеров: %w", err)
+	}
+	logrus.Infof("Менеджеров в распределении заявок: %d, стратегия: %s", len(router.Managers), router.Strategy)
+
+	topicStore, err := storage.NewFileTopicStore(config.AppConfig.TopicsFile)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия хранилища тем форума: %w", err)
+	}
+
+	formService := services.NewFormService(botAPI, leadRepository, router, topicStore, services.SubmitLimits{
+		Count:           config.AppConfig.SubmitRateLimit,
+		Window:          config.AppConfig.SubmitRateWindow,
+		DuplicateWindow: config.AppConfig.DuplicateWindow,
+	})
+	commandHandler := handlers.NewCommandHandler(botAPI, stateStore, settingsStore, form)
 	convHandler := handlers.NewConversationHandler(commandHandler, formService)
+	managerHandler := handlers.NewManagerHandler(botAPI, formService)
+	reminders := services.NewReminderService(botAPI, stateStore, utils.SystemClock{},
+		config.AppConfig.ReminderAfter, config.AppConfig.StateTTL)
 
 	return &TravelBot{
 		botAPI:         botAPI,
 		commandHandler: commandHandler,
 		convHandler:    convHandler,
+		managerHandler: managerHandler,
 		formService:    formService,
+		reminders:      reminders,
+		bans:           banStore,
+		limiter: services.NewRateLimiter(utils.SystemClock{},
+			config.AppConfig.MessageRateLimit, config.AppConfig.MessageRateWindow),
 	}, nil
 }
 
 func (tb *TravelBot) Start() error {
 	logrus.Info("Бот запускается...")
 
+	dispatcher := newDispatcher(config.AppConfig.WorkerCount, updateQueueSize, tb.handleUpdate)
+	defer dispatcher.Stop()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go tb.formService.RunOutbox(config.AppConfig.OutboxInterval, stop)
+	go tb.reminders.Run(config.AppConfig.ReminderInterval, stop)
+
+	if config.AppConfig.WebhookURL != "" {
+		return tb.startWebhook(dispatcher)
+	}
+
+	return tb.startPolling(dispatcher)
+}
+
+func (tb *TravelBot) startPolling(dispatcher *dispatcher) error {
+	// Telegram не отдает обновления через getUpdates, пока установлен вебхук.
+	if _, err := tb.botAPI.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return fmt.Errorf("ошибка удаления вебхука: %w", err)
+	}
+
 	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
+	u.Timeout = config.AppConfig.PollingTimeout
 
 	updates := tb.botAPI.GetUpdatesChan(u)
 
+	logrus.Info("Бот работает в режиме long polling")
+
 	for update := range updates {
-		if update.CallbackQuery != nil {
-			tb.handleCallbackQuery(update)
-			continue
-		}
+		dispatcher.Dispatch(update)
+	}
 
-		if update.Message == nil {
-			continue
-		}
+	return nil
+}
 
-		logrus.WithFields(logrus.Fields{
-			"user_id":  update.Message.From.ID,
-			"username": update.Message.From.UserName,
-			"text":     update.Message.Text,
-			"chat_id":  update.Message.Chat.ID,
-		}).Debug("Получено сообщение")
-
-		if update.Message.IsCommand() {
-			tb.handleCommand(update)
-		} else {
-			tb.convHandler.HandleMessage(update)
+func (tb *TravelBot) handleUpdate(update tgbotapi.Update) {
+	if tb.blocked(update) {
+		return
+	}
+
+	if update.CallbackQuery != nil {
+		tb.handleCallbackQuery(update)
+		return
+	}
+
+	if update.Message == nil {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":  update.Message.From.ID,
+		"username": update.Message.From.UserName,
+		"text":     update.Message.Text,
+		"chat_id":  update.Message.Chat.ID,
+	}).Debug("Получено сообщение")
+
+	if update.Message.IsCommand() {
+		tb.handleCommand(update)
+		return
+	}
+
+	// Ответы менеджера в переписке по заявке пересылаются клиенту. Остальные
+	// сообщения группы менеджеров — их обсуждения, на них бот не отвечает.
+	if tb.formService.IsManagerChat(update.Message.Chat.ID) {
+		if tb.managerHandler.HandleMessage(update) || !update.Message.Chat.IsPrivate() {
+			return
 		}
 	}
 
-	return nil
+	tb.convHandler.HandleMessage(update)
 }
 
 func (tb *TravelBot) handleCallbackQuery(update tgbotapi.Update) {
@@ -89,47 +201,75 @@ func (tb *TravelBot) handleCallbackQuery(update tgbotapi.Update) {
 		"callback_data": update.CallbackQuery.Data,
 	}).Info("Обработка callback query")
 
-	_, step, exists := tb.commandHandler.GetUserState(userID)
-	if !exists {
-		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "Диалог не активен. Начните заново /newrequest")
-		tb.botAPI.Send(callback)
+	if strings.HasPrefix(update.CallbackQuery.Data, services.LeadCallbackPrefix) {
+		tb.managerHandler.HandleCallback(update)
 		return
 	}
 
-	if step == handlers.STEP_HOTEL_LEVEL {
-		tb.convHandler.HandleMessage(update)
-	} else {
-		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "Неверный шаг диалога")
-		tb.botAPI.Send(callback)
-	}
+	tb.convHandler.HandleMessage(update)
 }
 
 func (tb *TravelBot) handleCommand(update tgbotapi.Update) {
-	switch update.Message.Command() {
+	command := update.Message.Command()
+	if adminCommands[command] && !config.AppConfig.IsAdmin(update.Message.From.ID) {
+		logrus.WithFields(logrus.Fields{
+			"user_id":  update.Message.From.ID,
+			"username": update.Message.From.UserName,
+			"command":  command,
+		}).Warn("Служебная команда от пользователя без прав администратора")
+		tb.replyUnknownCommand(update)
+		return
+	}
+
+	switch command {
 	case "start":
 		tb.commandHandler.HandleStart(update)
 	case "help":
 		tb.commandHandler.HandleHelp(update)
 	case "newrequest":
 		tb.commandHandler.HandleNewRequest(update)
+	case "repeat":
+		tb.convHandler.HandleRepeat(update)
+	case "myrequests":
+		tb.convHandler.HandleMyRequests(update)
+	case "back":
+		tb.convHandler.HandleBack(update)
 	case "cancel":
 		tb.commandHandler.HandleCancel(update)
+	case "language":
+		tb.commandHandler.HandleLanguage(update)
 	case "test":
 		tb.handleTestCommand(update)
 	case "config":
 		tb.handleConfig(update)
+	case "ban":
+		tb.handleBan(update)
+	case "unban":
+		tb.handleUnban(update)
+	case "banlist":
+		tb.handleBanList(update)
+	case "leads":
+		if !tb.formService.IsManagerChat(update.Message.Chat.ID) {
+			tb.replyUnknownCommand(update)
+			return
+		}
+		tb.managerHandler.HandleLeads(update)
 	case "myid":
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
 			fmt.Sprintf("Ваш Chat ID: `%d`", update.Message.Chat.ID))
 		msg.ParseMode = "Markdown"
 		tb.botAPI.Send(msg)
 	default:
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"Неизвестная команда. Используйте /help для списка команд")
-		tb.botAPI.Send(msg)
+		tb.replyUnknownCommand(update)
 	}
 }
 
+func (tb *TravelBot) replyUnknownCommand(update tgbotapi.Update) {
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+		i18n.T(tb.commandHandler.Language(update.Message.From), "unknown_command"))
+	tb.botAPI.Send(msg)
+}
+
 func (tb *TravelBot) handleTestCommand(update tgbotapi.Update) {
 	logrus.Info("Вызвана тестовая команда /test")
 
@@ -139,7 +279,6 @@ func (tb *TravelBot) handleTestCommand(update tgbotapi.Update) {
 		TravelDates:      "тест",
 		Duration:         "7 дней",
 		Travelers:        "2 взрослых",
-		ChildAge:         "Нет детей",
 		Budget:           "100000 ₽",
 		VacationType:     "Пляжный",
 		HotelLevel:       "4★",
@@ -0,0 +1,14 @@
+package bot
+
+import "testing"
+
+func TestAdminCommands(t *testing.T) {
+	for _, command := range []string{"test", "config", "ban", "unban", "banlist"} {
+		if !adminCommands[command] {
+			t.Errorf("/%s должна быть доступна только администраторам", command)
+		}
+	}
+	if adminCommands["myid"] {
+		t.Error("/myid должна быть доступна всем: без нее не узнать ID для ADMIN_IDS")
+	}
+}
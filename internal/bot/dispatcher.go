@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// dispatcher обрабатывает обновления в нескольких воркерах. Обновления одного
+// пользователя всегда попадают в одну очередь, поэтому обрабатываются строго
+// в порядке поступления, а медленный ответ одному клиенту не задерживает остальных.
+type dispatcher struct {
+	queues []chan tgbotapi.Update
+	handle func(tgbotapi.Update)
+	wg     sync.WaitGroup
+}
+
+func newDispatcher(workers, queueSize int, handle func(tgbotapi.Update)) *dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+
+	d := &dispatcher{
+		queues: make([]chan tgbotapi.Update, workers),
+		handle: handle,
+	}
+
+	for i := range d.queues {
+		d.queues[i] = make(chan tgbotapi.Update, queueSize)
+		d.wg.Add(1)
+		go d.work(d.queues[i])
+	}
+
+	return d
+}
+
+// Dispatch ставит обновление в очередь воркера, закрепленного за пользователем.
+// Если очередь заполнена, Dispatch ждет места: обновления не теряются, но прием
+// новых обновлений от всех пользователей приостанавливается, пока воркер не
+// разберет свою очередь. Размер очереди задает, сколько можно накопить до этого.
+func (d *dispatcher) Dispatch(update tgbotapi.Update) {
+	userID := updateUserID(update)
+	if userID < 0 {
+		userID = -userID
+	}
+
+	queue := d.queues[userID%int64(len(d.queues))]
+	select {
+	case queue <- update:
+	default:
+		logrus.WithFields(logrus.Fields{
+			"user_id":    updateUserID(update),
+			"queue_size": cap(queue),
+		}).Warn("Очередь обработки обновлений переполнена, прием обновлений приостановлен")
+		queue <- update
+	}
+}
+
+// Stop дожидается обработки всех поставленных в очередь обновлений.
+func (d *dispatcher) Stop() {
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.wg.Wait()
+}
+
+func (d *dispatcher) work(queue <-chan tgbotapi.Update) {
+	defer d.wg.Done()
+
+	for update := range queue {
+		d.safeHandle(update)
+	}
+}
+
+func (d *dispatcher) safeHandle(update tgbotapi.Update) {
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.WithFields(logrus.Fields{
+				"update_id": update.UpdateID,
+				"user_id":   updateUserID(update),
+				"panic":     r,
+			}).Error("Паника при обработке обновления")
+		}
+	}()
+
+	d.handle(update)
+}
+
+func updateUserID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil && update.Message.From != nil:
+		return update.Message.From.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.From != nil:
+		return update.CallbackQuery.From.ID
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func messageUpdate(updateID int, userID int64) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: updateID,
+		Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: userID},
+			Chat: &tgbotapi.Chat{ID: userID},
+		},
+	}
+}
+
+// TestDispatcherKeepsPerUserOrder рассчитан на запуск с -race: обновления
+// нескольких пользователей обрабатываются параллельно, но обновления одного
+// пользователя — строго по порядку.
+func TestDispatcherKeepsPerUserOrder(t *testing.T) {
+	const users, updates = 5, 50
+
+	var mu sync.Mutex
+	handled := make(map[int64][]int)
+
+	// Очередь меньше числа обновлений, чтобы Dispatch упирался в заполненную очередь.
+	d := newDispatcher(3, 2, func(update tgbotapi.Update) {
+		userID := update.Message.From.ID
+		mu.Lock()
+		handled[userID] = append(handled[userID], update.UpdateID)
+		mu.Unlock()
+	})
+
+	updateID := 0
+	for i := 0; i < updates; i++ {
+		for userID := int64(1); userID <= users; userID++ {
+			updateID++
+			d.Dispatch(messageUpdate(updateID, userID))
+		}
+	}
+	d.Stop()
+
+	for userID := int64(1); userID <= users; userID++ {
+		ids := handled[userID]
+		if len(ids) != updates {
+			t.Fatalf("user %d: handled %d updates, want %d", userID, len(ids), updates)
+		}
+		for i := 1; i < len(ids); i++ {
+			if ids[i] <= ids[i-1] {
+				t.Fatalf("user %d: updates out of order: %v", userID, ids)
+			}
+		}
+	}
+}
+
+func TestDispatcherRecoversFromPanic(t *testing.T) {
+	var mu sync.Mutex
+	var handled []int
+
+	d := newDispatcher(1, 4, func(update tgbotapi.Update) {
+		if update.UpdateID == 1 {
+			panic("boom")
+		}
+		mu.Lock()
+		handled = append(handled, update.UpdateID)
+		mu.Unlock()
+	})
+	d.Dispatch(messageUpdate(1, 7))
+	d.Dispatch(messageUpdate(2, 7))
+	d.Stop()
+
+	if len(handled) != 1 || handled[0] != 2 {
+		t.Errorf("handled = %v, want [2]", handled)
+	}
+}
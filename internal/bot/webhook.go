@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"pumpkin_travel_tg_bot/config"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// startWebhook регистрирует вебхук в Telegram и принимает обновления по HTTP.
+// TLS обычно терминируется на обратном прокси хостинга, поэтому сервер слушает обычный HTTP.
+func (tb *TravelBot) startWebhook(dispatcher *dispatcher) error {
+	webhookURL, err := url.Parse(config.AppConfig.WebhookURL)
+	if err != nil {
+		return fmt.Errorf("некорректный WEBHOOK_URL: %w", err)
+	}
+
+	params := tgbotapi.Params{"url": webhookURL.String()}
+	params.AddNonEmpty("secret_token", config.AppConfig.WebhookSecret)
+	if _, err := tb.botAPI.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("ошибка установки вебхука: %w", err)
+	}
+
+	path := webhookURL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, tb.webhookHandler(dispatcher))
+
+	logrus.WithFields(logrus.Fields{
+		"url":    webhookURL.String(),
+		"listen": config.AppConfig.WebhookListenAddr,
+	}).Info("Бот работает в режиме вебхука")
+
+	return http.ListenAndServe(config.AppConfig.WebhookListenAddr, mux)
+}
+
+func (tb *TravelBot) webhookHandler(dispatcher *dispatcher) http.HandlerFunc {
+	secret := []byte(config.AppConfig.WebhookSecret)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Пустой секрет отклоняет все запросы: config.validate не запускает вебхук без него.
+		token := []byte(r.Header.Get(secretTokenHeader))
+		if len(secret) == 0 || subtle.ConstantTimeCompare(token, secret) != 1 {
+			logrus.WithField("remote_addr", r.RemoteAddr).Warn("Запрос к вебхуку с неверным секретным токеном")
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		update, err := tb.botAPI.HandleUpdate(r)
+		if err != nil {
+			logrus.WithError(err).Warn("Не удалось разобрать обновление из вебхука")
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		dispatcher.Dispatch(*update)
+		w.WriteHeader(http.StatusOK)
+	}
+}
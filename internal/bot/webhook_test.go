@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"pumpkin_travel_tg_bot/config"
+	"strings"
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestWebhookHandlerChecksSecret(t *testing.T) {
+	previous := config.AppConfig
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	tests := []struct {
+		name       string
+		secret     string
+		header     string
+		wantStatus int
+		wantUpdate bool
+	}{
+		{"valid secret", "s3cret", "s3cret", http.StatusOK, true},
+		{"wrong secret", "s3cret", "guess", http.StatusForbidden, false},
+		{"missing header", "s3cret", "", http.StatusForbidden, false},
+		{"no secret configured", "", "", http.StatusForbidden, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.AppConfig.WebhookSecret = tt.secret
+
+			var mu sync.Mutex
+			var handled []tgbotapi.Update
+			d := newDispatcher(1, 1, func(update tgbotapi.Update) {
+				mu.Lock()
+				handled = append(handled, update)
+				mu.Unlock()
+			})
+
+			tb := &TravelBot{botAPI: &tgbotapi.BotAPI{}}
+			body := `{"update_id": 1, "message": {"message_id": 1, "chat": {"id": 5}, "from": {"id": 5}, "text": "/ban 1"}}`
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			if tt.header != "" {
+				req.Header.Set(secretTokenHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			tb.webhookHandler(d)(rec, req)
+			d.Stop()
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := len(handled) == 1; got != tt.wantUpdate {
+				t.Errorf("update dispatched = %v, want %v", got, tt.wantUpdate)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+// BudgetRange — бюджет, разобранный из ответа клиента. Нулевая граница
+// означает, что она не указана: «до 80 000 ₽» дает только Max.
+type BudgetRange struct {
+	Min      int64  `json:"min,omitempty"`
+	Max      int64  `json:"max,omitempty"`
+	Currency string `json:"currency,omitempty"`
+	// Flexible — клиент не ограничивает бюджет («Без строгих рамок»).
+	Flexible bool `json:"flexible,omitempty"`
+}
+
+const (
+	CurrencyRUB = "RUB"
+	CurrencyUSD = "USD"
+	CurrencyEUR = "EUR"
+)
+
+// Overlaps сообщает, пересекается ли бюджет с диапазоном [min, max].
+// Нулевая граница диапазона не ограничивает его, гибкий бюджет подходит под любой диапазон.
+func (b *BudgetRange) Overlaps(min, max int64) bool {
+	if b.Flexible {
+		return true
+	}
+	if max != 0 && b.Min > max {
+		return false
+	}
+	if min != 0 && b.Max != 0 && b.Max < min {
+		return false
+	}
+	return true
+}
+
+// Upper возвращает верхнюю границу бюджета, а если она не указана — нижнюю.
+func (b *BudgetRange) Upper() int64 {
+	if b.Max != 0 {
+		return b.Max
+	}
+	return b.Min
+}
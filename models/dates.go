@@ -0,0 +1,41 @@
+package models
+
+import (
+	"pumpkin_travel_tg_bot/i18n"
+	"time"
+)
+
+// DateWindow — окно дат поездки, разобранное из ответа клиента.
+type DateWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Flexible — клиенту подходят любые даты внутри окна («Июнь», «Любые даты февраля»).
+	Flexible bool `json:"flexible,omitempty"`
+}
+
+// Days возвращает длину окна в днях, включая первый и последний день.
+func (w *DateWindow) Days() int {
+	return int(w.End.Sub(w.Start).Hours()/24) + 1
+}
+
+// IsPast сообщает, что окно целиком закончилось до указанного момента.
+func (w *DateWindow) IsPast(now time.Time) bool {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return w.End.Before(today)
+}
+
+func (w *DateWindow) String() string {
+	return w.Text(i18n.Default)
+}
+
+// Text описывает окно дат на языке lang: «10.05.2026 – 20.05.2026 (гибкие даты)».
+func (w *DateWindow) Text(lang string) string {
+	text := w.Start.Format("02.01.2006")
+	if !w.End.Equal(w.Start) {
+		text += " – " + w.End.Format("02.01.2006")
+	}
+	if w.Flexible {
+		text += " " + i18n.T(lang, "dates.flexible")
+	}
+	return text
+}
@@ -0,0 +1,14 @@
+package models
+
+import "fmt"
+
+// DepartureAirport — город вылета из справочника с IATA-кодом города.
+type DepartureAirport struct {
+	City string `json:"city"`
+	IATA string `json:"iata"`
+}
+
+// String возвращает город с кодом, например «Москва (MOW)».
+func (d *DepartureAirport) String() string {
+	return fmt.Sprintf("%s (%s)", d.City, d.IATA)
+}
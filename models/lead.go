@@ -0,0 +1,130 @@
+package models
+
+import (
+	"pumpkin_travel_tg_bot/i18n"
+	"time"
+)
+
+type LeadStatus string
+
+const (
+	LeadStatusNew        LeadStatus = "new"
+	LeadStatusInProgress LeadStatus = "in_progress"
+	LeadStatusContacted  LeadStatus = "contacted"
+	LeadStatusClosed     LeadStatus = "closed"
+	LeadStatusRejected   LeadStatus = "rejected"
+	LeadStatusCancelled  LeadStatus = "cancelled"
+)
+
+// Title возвращает название статуса на языке lang для карточки заявки.
+func (s LeadStatus) Title(lang string) string {
+	switch s {
+	case LeadStatusNew, LeadStatusInProgress, LeadStatusContacted, LeadStatusClosed, LeadStatusRejected, LeadStatusCancelled:
+		return i18n.T(lang, "status."+string(s))
+	}
+	return string(s)
+}
+
+// IsFinal сообщает, что работа с заявкой завершена и статус больше не меняется.
+func (s LeadStatus) IsFinal() bool {
+	return s == LeadStatusClosed || s == LeadStatusRejected || s == LeadStatusCancelled
+}
+
+// CanChangeTo сообщает, можно ли перевести заявку в статус next:
+// заявка движется только вперед — от новой к закрытой, отклоненной
+// или отмененной клиентом.
+func (s LeadStatus) CanChangeTo(next LeadStatus) bool {
+	switch s {
+	case LeadStatusNew:
+		return next != LeadStatusNew
+	case LeadStatusInProgress:
+		return next == LeadStatusContacted || next.IsFinal()
+	case LeadStatusContacted:
+		return next.IsFinal()
+	}
+	return false
+}
+
+// Lead — подтвержденная клиентом заявка с присвоенным номером.
+type Lead struct {
+	Number    int64         `json:"number"`
+	Status    LeadStatus    `json:"status"`
+	Request   TravelRequest `json:"request"`
+	UserInfo  UserInfo      `json:"user_info"`
+	Delivery  LeadDelivery  `json:"delivery"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+
+	// AssignedChatID и AssignedTo — чат и имя менеджера, которому направлена заявка.
+	AssignedChatID int64  `json:"assigned_chat_id,omitempty"`
+	AssignedTo     string `json:"assigned_to,omitempty"`
+
+	// HandledBy и HandledAt — менеджер, последним изменивший статус заявки, и когда.
+	HandledBy *UserInfo `json:"handled_by,omitempty"`
+	HandledAt time.Time `json:"handled_at,omitempty"`
+}
+
+// LeadDelivery — состояние доставки заявки менеджеру.
+type LeadDelivery struct {
+	Delivered   bool      `json:"delivered"`
+	DeliveredAt time.Time `json:"delivered_at,omitempty"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+
+	// ChatID и MessageID — доставленная карточка заявки, которую обновляют при смене статуса.
+	ChatID    int64 `json:"chat_id,omitempty"`
+	MessageID int   `json:"message_id,omitempty"`
+	// TopicID — тема форума с карточкой заявки; OwnTopic — тема создана
+	// только для этой заявки, и все сообщения в ней относятся к заявке.
+	TopicID  int  `json:"topic_id,omitempty"`
+	OwnTopic bool `json:"own_topic,omitempty"`
+	// RelayMessageIDs — сообщения клиента, пересланные в тот же чат ответом на карточку.
+	RelayMessageIDs []int `json:"relay_message_ids,omitempty"`
+}
+
+// HasManagerMessage сообщает, относится ли сообщение в чате менеджера к заявке:
+// это ее карточка или пересланное сообщение клиента.
+func (l *Lead) HasManagerMessage(chatID int64, messageID int) bool {
+	if l.Delivery.ChatID != chatID || messageID == 0 {
+		return false
+	}
+	if l.Delivery.MessageID == messageID {
+		return true
+	}
+	// Сообщения в теме форума без явного ответа ссылаются на начало темы.
+	if l.Delivery.OwnTopic && l.Delivery.TopicID == messageID {
+		return true
+	}
+	for _, id := range l.Delivery.RelayMessageIDs {
+		if id == messageID {
+			return true
+		}
+	}
+	return false
+}
+
+// ToFormattedString — карточка заявки для менеджера на языке lang.
+func (l *Lead) ToFormattedString(lang string) string {
+	header := i18n.T(lang, "card.number", l.Number) + "\n"
+	if l.AssignedTo != "" {
+		header += i18n.T(lang, "card.manager", escapeHTML(l.AssignedTo)) + "\n"
+	}
+	return header + l.statusLine(lang) + l.Request.ToFormattedString(l.UserInfo, lang)
+}
+
+// statusLine — статус заявки для карточки менеджера; у новой заявки не выводится.
+func (l *Lead) statusLine(lang string) string {
+	if l.Status == LeadStatusNew || l.Status == "" {
+		return ""
+	}
+
+	line := i18n.T(lang, "card.status", l.Status.Title(lang))
+	if l.HandledBy != nil {
+		line += " — " + escapeHTML(l.HandledBy.DisplayName())
+	}
+	if !l.HandledAt.IsZero() {
+		line += ", " + l.HandledAt.Format(i18n.T(lang, "format.datetime"))
+	}
+	return line + "\n"
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"fmt"
+	"pumpkin_travel_tg_bot/i18n"
+)
+
+// NightsRange — длительность отдыха в ночах, разобранная из ответа Duration.
+// Нулевой Max означает, что верхняя граница не указана: «от 7 ночей».
+type NightsRange struct {
+	Min int `json:"min"`
+	Max int `json:"max,omitempty"`
+}
+
+func (n *NightsRange) String() string {
+	return n.Text(i18n.Default)
+}
+
+// Text описывает длительность на языке lang: «7 ночей», «7–10 ночей», «от 7 ночей».
+func (n *NightsRange) Text(lang string) string {
+	if n.Max == 0 {
+		return i18n.T(lang, "nights.from", n.Min, i18n.Plural(lang, "nights_from", n.Min))
+	}
+	if n.Min == n.Max {
+		return fmt.Sprintf("%d %s", n.Max, i18n.Plural(lang, "nights", n.Max))
+	}
+	return fmt.Sprintf("%d–%d %s", n.Min, n.Max, i18n.Plural(lang, "nights", n.Max))
+}
+
+// FitsWindow сообщает, помещается ли минимальная длительность в окно дат.
+// Гибкое окно ограничивает только дату вылета, поэтому с ним противоречий нет.
+func (n *NightsRange) FitsWindow(window *DateWindow) bool {
+	if window == nil || window.Flexible {
+		return true
+	}
+	return n.Min <= window.Days()-1
+}
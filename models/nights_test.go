@@ -0,0 +1,28 @@
+package models
+
+import (
+	"pumpkin_travel_tg_bot/i18n"
+	"testing"
+)
+
+func TestNightsRangeText(t *testing.T) {
+	tests := []struct {
+		nights NightsRange
+		lang   string
+		want   string
+	}{
+		{NightsRange{Min: 7, Max: 7}, i18n.RU, "7 ночей"},
+		{NightsRange{Min: 7, Max: 10}, i18n.RU, "7–10 ночей"},
+		{NightsRange{Min: 7}, i18n.RU, "от 7 ночей"},
+		{NightsRange{Min: 1}, i18n.RU, "от 1 ночи"},
+		{NightsRange{Min: 3}, i18n.RU, "от 3 ночей"},
+		{NightsRange{Min: 1, Max: 1}, i18n.EN, "1 night"},
+		{NightsRange{Min: 7}, i18n.EN, "from 7 nights"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.nights.Text(tt.lang); got != tt.want {
+			t.Errorf("%+v.Text(%s) = %q, want %q", tt.nights, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"fmt"
+	"pumpkin_travel_tg_bot/i18n"
+	"strings"
+)
+
+// TravelParty — состав туристов, разобранный из ответа Travelers.
+type TravelParty struct {
+	Adults   int `json:"adults"`
+	Children int `json:"children"`
+	// ChildAges — возраст каждого ребенка в годах, по одному значению на ребенка.
+	ChildAges []int `json:"child_ages,omitempty"`
+}
+
+// ChildAgesText описывает возраст детей на языке lang: «3 года, 7 лет».
+func (p *TravelParty) ChildAgesText(lang string) string {
+	parts := make([]string, 0, len(p.ChildAges))
+	for _, age := range p.ChildAges {
+		parts = append(parts, formatAge(lang, age))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatAge(lang string, age int) string {
+	if age == 0 {
+		return i18n.T(lang, "age.infant")
+	}
+	return fmt.Sprintf("%d %s", age, i18n.Plural(lang, "years", age))
+}
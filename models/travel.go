@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"pumpkin_travel_tg_bot/i18n"
 	"strings"
 	"time"
 )
@@ -19,6 +20,46 @@ type TravelRequest struct {
 	MealPlan         string    `json:"meal_plan"`
 	ImportantFactors string    `json:"important_factors"`
 	CreatedAt        time.Time `json:"created_at"`
+
+	// Language — язык, на котором клиент заполняет анкету и получает сообщения бота.
+	Language string `json:"language,omitempty"`
+
+	// Destinations — направления из ответа Destination в каноническом виде из каталога.
+	Destinations []string `json:"destinations,omitempty"`
+	// DestinationUndecided — клиент еще не выбрал направление или открыт к другим вариантам.
+	DestinationUndecided bool `json:"destination_undecided,omitempty"`
+	// Departure — город вылета из справочника, найденный в ответе DepartureCity.
+	Departure *DepartureAirport `json:"departure,omitempty"`
+	// DateWindow — окно дат, разобранное из ответа TravelDates.
+	DateWindow *DateWindow `json:"date_window,omitempty"`
+	// Nights — длительность в ночах, разобранная из ответа Duration.
+	Nights *NightsRange `json:"nights,omitempty"`
+	// Party — состав туристов, разобранный из ответа Travelers, с возрастом детей.
+	Party *TravelParty `json:"party,omitempty"`
+	// BudgetRange — бюджет, разобранный из ответа Budget.
+	BudgetRange *BudgetRange `json:"budget_range,omitempty"`
+
+	// ExtraAnswers — ответы на вопросы анкеты, для которых нет отдельного поля.
+	ExtraAnswers []ExtraAnswer `json:"extra_answers,omitempty"`
+}
+
+type ExtraAnswer struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// AnswerTranslator переводит ответ на вопрос анкеты key на язык lang.
+type AnswerTranslator func(key, lang, answer string) string
+
+// answerTranslator переводит в карточках варианты ответа, которые хранятся
+// в заявке на языке анкеты. Без него ответы показываются как записаны.
+var answerTranslator AnswerTranslator
+
+// SetAnswerTranslator задает перевод ответов в карточках заявок. Вызывается
+// при запуске бота после загрузки анкеты.
+func SetAnswerTranslator(translator AnswerTranslator) {
+	answerTranslator = translator
 }
 
 type UserInfo struct {
@@ -28,6 +69,139 @@ type UserInfo struct {
 	Username  string `json:"username"`
 }
 
+// DisplayName возвращает имя пользователя для подписи: имя и фамилию или @username.
+func (u UserInfo) DisplayName() string {
+	name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	if u.Username != "" {
+		if name == "" {
+			return "@" + u.Username
+		}
+		return name + " (@" + u.Username + ")"
+	}
+	if name == "" {
+		return fmt.Sprintf("ID %d", u.ID)
+	}
+	return name
+}
+
+// Clone возвращает копию заявки, не разделяющую с оригиналом срезы и
+// разобранные поля: изменения копии не затрагивают оригинал.
+func (tr TravelRequest) Clone() TravelRequest {
+	clone := tr
+	clone.Destinations = append([]string(nil), tr.Destinations...)
+	clone.ExtraAnswers = append([]ExtraAnswer(nil), tr.ExtraAnswers...)
+	if tr.Departure != nil {
+		departure := *tr.Departure
+		clone.Departure = &departure
+	}
+	if tr.DateWindow != nil {
+		window := *tr.DateWindow
+		clone.DateWindow = &window
+	}
+	if tr.Nights != nil {
+		nights := *tr.Nights
+		clone.Nights = &nights
+	}
+	if tr.Party != nil {
+		party := *tr.Party
+		party.ChildAges = append([]int(nil), tr.Party.ChildAges...)
+		clone.Party = &party
+	}
+	if tr.BudgetRange != nil {
+		budget := *tr.BudgetRange
+		clone.BudgetRange = &budget
+	}
+	return clone
+}
+
+// Field возвращает ответ на вопрос анкеты по ключу поля.
+func (tr *TravelRequest) Field(key string) string {
+	if field := tr.fieldPtr(key); field != nil {
+		return *field
+	}
+	for _, extra := range tr.ExtraAnswers {
+		if extra.Key == key {
+			return extra.Value
+		}
+	}
+	return ""
+}
+
+// SetField сохраняет ответ на вопрос анкеты. Ответы на вопросы без
+// отдельного поля попадают в ExtraAnswers с подписью label.
+func (tr *TravelRequest) SetField(key, label, value string) {
+	if field := tr.fieldPtr(key); field != nil {
+		*field = value
+		return
+	}
+	for i := range tr.ExtraAnswers {
+		if tr.ExtraAnswers[i].Key == key {
+			tr.ExtraAnswers[i].Label = label
+			tr.ExtraAnswers[i].Value = value
+			return
+		}
+	}
+	tr.ExtraAnswers = append(tr.ExtraAnswers, ExtraAnswer{Key: key, Label: label, Value: value})
+}
+
+// answerFieldKeys — ключи ответов, для которых в заявке есть отдельные поля.
+var answerFieldKeys = []string{
+	"destination", "departure_city", "travel_dates", "duration", "travelers", "child_age",
+	"budget", "vacation_type", "hotel_level", "meal_plan", "important_factors",
+}
+
+// SameAnswers сообщает, что в заявках одинаковые ответы без учета регистра
+// и пробелов по краям. Разобранные поля не сравниваются: они выводятся из ответов.
+func (tr *TravelRequest) SameAnswers(other *TravelRequest) bool {
+	for _, key := range answerFieldKeys {
+		if !sameAnswer(tr.Field(key), other.Field(key)) {
+			return false
+		}
+	}
+
+	if len(tr.ExtraAnswers) != len(other.ExtraAnswers) {
+		return false
+	}
+	for _, extra := range tr.ExtraAnswers {
+		if !sameAnswer(extra.Value, other.Field(extra.Key)) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameAnswer(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+func (tr *TravelRequest) fieldPtr(key string) *string {
+	switch key {
+	case "destination":
+		return &tr.Destination
+	case "departure_city":
+		return &tr.DepartureCity
+	case "travel_dates":
+		return &tr.TravelDates
+	case "duration":
+		return &tr.Duration
+	case "travelers":
+		return &tr.Travelers
+	case "child_age":
+		return &tr.ChildAge
+	case "budget":
+		return &tr.Budget
+	case "vacation_type":
+		return &tr.VacationType
+	case "hotel_level":
+		return &tr.HotelLevel
+	case "meal_plan":
+		return &tr.MealPlan
+	case "important_factors":
+		return &tr.ImportantFactors
+	}
+	return nil
+}
+
 func escapeHTML(text string) string {
 	replacements := []struct {
 		old string
@@ -48,80 +222,144 @@ func escapeHTML(text string) string {
 	return result
 }
 
-func (tr *TravelRequest) ToFormattedString(userInfo UserInfo) string {
+// ToFormattedString — карточка заявки для менеджера на языке lang.
+func (tr *TravelRequest) ToFormattedString(userInfo UserInfo, lang string) string {
 	var builder strings.Builder
 
-	builder.WriteString("<b>🌴 НОВАЯ ЗАЯВКА НА ПОДБОР ТУРА</b>\n\n")
+	builder.WriteString(i18n.T(lang, "card.title") + "\n\n")
 
 	// Информация о клиенте (для менеджера)
-	builder.WriteString("<b>👤 Клиент:</b> ")
+	builder.WriteString(i18n.T(lang, "card.client") + " ")
 	if userInfo.FirstName != "" || userInfo.LastName != "" {
 		builder.WriteString(escapeHTML(userInfo.FirstName + " " + userInfo.LastName))
 	}
 	if userInfo.Username != "" {
-		builder.WriteString(fmt.Sprintf("\n<b>📱 @:</b> %s", escapeHTML(userInfo.Username)))
+		builder.WriteString("\n" + i18n.T(lang, "card.username", escapeHTML(userInfo.Username)))
 	}
 	builder.WriteString(fmt.Sprintf("\n<b>🆔 ID:</b> %d\n", userInfo.ID))
+	// Менеджеру важно знать, на каком языке писать клиенту.
+	if tr.Language != "" && tr.Language != lang {
+		builder.WriteString(i18n.T(lang, "card.language", i18n.T(lang, "language."+tr.Language)) + "\n")
+	}
 
 	builder.WriteString("\n<b>═══════════════════════════════════</b>\n\n")
 
 	// Данные заявки
-	writeFieldHTML(&builder, "1️⃣ Куда планируете поездку?", tr.Destination)
-	writeFieldHTML(&builder, "2️⃣ Город вылета", tr.DepartureCity)
-	writeFieldHTML(&builder, "3️⃣ Даты поездки", tr.TravelDates)
-	writeFieldHTML(&builder, "4️⃣ Длительность отдыха", tr.Duration)
-	writeFieldHTML(&builder, "5️⃣ Количество туристов", tr.Travelers)
-
-	if tr.ChildAge != "" && tr.ChildAge != "Нет детей" {
-		writeFieldHTML(&builder, "   Возраст ребенка", tr.ChildAge)
-	}
-
-	writeFieldHTML(&builder, "6️⃣ Бюджет на всех", tr.Budget)
-	writeFieldHTML(&builder, "7️⃣ Тип отдыха", tr.VacationType)
-	writeFieldHTML(&builder, "8️⃣ Уровень отеля", tr.HotelLevel)
-	writeFieldHTML(&builder, "9️⃣ Тип питания", tr.MealPlan)
-	writeFieldHTML(&builder, "🔟 Принципиально важно", tr.ImportantFactors)
-
-	builder.WriteString("\n<b>═══════════════════════════════════</b>\n")
-	builder.WriteString(fmt.Sprintf("<b>📅 Заявка создана:</b> %s\n",
-		tr.CreatedAt.Format("02.01.2006 в 15:04")))
+	writeFieldHTML(&builder, lang, i18n.T(lang, "card.destination"), tr.destinationForManager(lang))
+	writeFieldHTML(&builder, lang, i18n.T(lang, "card.departure"), tr.departureForManager())
+	writeFieldHTML(&builder, lang, i18n.T(lang, "card.travel_dates"), tr.travelDatesForManager(lang))
+	writeFieldHTML(&builder, lang, i18n.T(lang, "card.duration"), tr.durationForManager(lang))
+	tr.writeAnswers(&builder, lang)
 
 	return builder.String()
 }
 
+// ToClientPreview — заявка для проверки клиентом на языке, на котором он ее заполнял.
 func (tr *TravelRequest) ToClientPreview() string {
+	lang := tr.Language
 	var builder strings.Builder
 
-	builder.WriteString("<b>🌴 ВАША ЗАЯВКА НА ПОДБОР ТУРА</b>\n\n")
+	builder.WriteString(i18n.T(lang, "card.preview_title") + "\n\n")
 
 	builder.WriteString("<b>═══════════════════════════════════</b>\n\n")
 
-	writeFieldHTML(&builder, "1️⃣ Куда планируете поездку?", tr.Destination)
-	writeFieldHTML(&builder, "2️⃣ Город вылета", tr.DepartureCity)
-	writeFieldHTML(&builder, "3️⃣ Даты поездки", tr.TravelDates)
-	writeFieldHTML(&builder, "4️⃣ Длительность отдыха", tr.Duration)
-	writeFieldHTML(&builder, "5️⃣ Количество туристов", tr.Travelers)
+	writeFieldHTML(&builder, lang, i18n.T(lang, "card.destination"), tr.Destination)
+	writeFieldHTML(&builder, lang, i18n.T(lang, "card.departure"), tr.DepartureCity)
+	writeFieldHTML(&builder, lang, i18n.T(lang, "card.travel_dates"), tr.TravelDates)
+	writeFieldHTML(&builder, lang, i18n.T(lang, "card.duration"), tr.Duration)
+	tr.writeAnswers(&builder, lang)
 
-	if tr.ChildAge != "" && tr.ChildAge != "Нет детей" {
-		writeFieldHTML(&builder, "   Возраст ребенка", tr.ChildAge)
+	return builder.String()
+}
+
+// writeAnswers дописывает в карточку ответы, которые менеджер и клиент видят одинаково.
+func (tr *TravelRequest) writeAnswers(builder *strings.Builder, lang string) {
+	writeFieldHTML(builder, lang, i18n.T(lang, "card.travelers"), tr.Travelers)
+
+	// Возраст показываем, только если в составе туристов есть дети: в старых
+	// заявках пропущенный вопрос хранит текст-заглушку вместо пустого ответа.
+	if tr.ChildAge != "" && tr.Party != nil && tr.Party.Children > 0 {
+		writeFieldHTML(builder, lang, tr.childAgeLabel(lang), tr.ChildAge)
 	}
 
-	writeFieldHTML(&builder, "6️⃣ Бюджет на всех", tr.Budget)
-	writeFieldHTML(&builder, "7️⃣ Тип отдыха", tr.VacationType)
-	writeFieldHTML(&builder, "8️⃣ Уровень отеля", tr.HotelLevel)
-	writeFieldHTML(&builder, "9️⃣ Тип питания", tr.MealPlan)
-	writeFieldHTML(&builder, "🔟 Принципиально важно", tr.ImportantFactors)
+	writeFieldHTML(builder, lang, i18n.T(lang, "card.budget"), tr.Budget)
+	writeFieldHTML(builder, lang, i18n.T(lang, "card.vacation_type"), translateAnswer("vacation_type", lang, tr.VacationType))
+	writeFieldHTML(builder, lang, i18n.T(lang, "card.hotel_level"), translateAnswer("hotel_level", lang, tr.HotelLevel))
+	writeFieldHTML(builder, lang, i18n.T(lang, "card.meal_plan"), translateAnswer("meal_plan", lang, tr.MealPlan))
+	writeFieldHTML(builder, lang, i18n.T(lang, "card.important_factors"), tr.ImportantFactors)
+	tr.writeExtraAnswers(builder, lang)
 
 	builder.WriteString("\n<b>═══════════════════════════════════</b>\n")
-	builder.WriteString(fmt.Sprintf("<b>📅 Заявка создана:</b> %s\n",
-		tr.CreatedAt.Format("02.01.2006 в 15:04")))
+	builder.WriteString(i18n.T(lang, "card.created", tr.CreatedAt.Format(i18n.T(lang, "format.datetime"))) + "\n")
+}
 
-	return builder.String()
+func (tr *TravelRequest) childAgeLabel(lang string) string {
+	if tr.Party != nil && tr.Party.Children > 1 {
+		return "   " + i18n.T(lang, "card.children_ages")
+	}
+	return "   " + i18n.T(lang, "card.child_age")
+}
+
+// destinationForManager дополняет ответ клиента направлениями из каталога.
+func (tr *TravelRequest) destinationForManager(lang string) string {
+	if tr.Destination == "" {
+		return tr.Destination
+	}
+
+	var recognized string
+	switch {
+	case len(tr.Destinations) > 0 && tr.DestinationUndecided:
+		recognized = i18n.T(lang, "card.open_to_other", strings.Join(tr.Destinations, ", "))
+	case len(tr.Destinations) > 0:
+		recognized = strings.Join(tr.Destinations, ", ")
+	case tr.DestinationUndecided:
+		recognized = i18n.T(lang, "card.undecided")
+	default:
+		return tr.Destination
+	}
+	return tr.Destination + "\n🗺 " + recognized
+}
+
+// departureForManager дополняет ответ клиента городом вылета с кодом.
+func (tr *TravelRequest) departureForManager() string {
+	if tr.Departure == nil || tr.DepartureCity == "" || tr.DepartureCity == tr.Departure.String() {
+		return tr.DepartureCity
+	}
+	return tr.DepartureCity + "\n✈️ " + tr.Departure.String()
+}
+
+// travelDatesForManager дополняет ответ клиента распознанным окном дат.
+func (tr *TravelRequest) travelDatesForManager(lang string) string {
+	if tr.DateWindow == nil || tr.TravelDates == "" {
+		return tr.TravelDates
+	}
+	return tr.TravelDates + "\n📆 " + tr.DateWindow.Text(lang)
+}
+
+// durationForManager дополняет ответ клиента распознанным количеством ночей.
+func (tr *TravelRequest) durationForManager(lang string) string {
+	if tr.Nights == nil || tr.Duration == "" {
+		return tr.Duration
+	}
+	return tr.Duration + "\n🌙 " + tr.Nights.Text(lang)
+}
+
+func (tr *TravelRequest) writeExtraAnswers(builder *strings.Builder, lang string) {
+	for _, extra := range tr.ExtraAnswers {
+		writeFieldHTML(builder, lang, "➕ "+extra.Label, translateAnswer(extra.Key, lang, extra.Value))
+	}
+}
+
+func translateAnswer(key, lang, answer string) string {
+	if answerTranslator == nil || answer == "" {
+		return answer
+	}
+	return answerTranslator(key, lang, answer)
 }
 
-func writeFieldHTML(builder *strings.Builder, name, value string) {
+func writeFieldHTML(builder *strings.Builder, lang, name, value string) {
 	if value == "" {
-		value = "Не указано"
+		value = i18n.T(lang, "card.not_specified")
 	}
 	builder.WriteString(fmt.Sprintf("<b>%s</b>\n%s\n\n",
 		escapeHTML(name),
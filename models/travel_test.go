@@ -0,0 +1,36 @@
+package models
+
+import (
+	"pumpkin_travel_tg_bot/i18n"
+	"strings"
+	"testing"
+)
+
+func TestClientPreviewShowsChildAgeOnlyWithChildren(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		party    *TravelParty
+		childAge string
+		want     bool
+	}{
+		{"ru без детей", i18n.RU, &TravelParty{Adults: 2}, "", false},
+		{"en без детей", i18n.EN, &TravelParty{Adults: 2}, "", false},
+		{"старая заявка с заглушкой", i18n.RU, &TravelParty{Adults: 2}, "Нет детей", false},
+		{"состав не распознан", i18n.EN, nil, "Нет детей", false},
+		{"ru с ребенком", i18n.RU, &TravelParty{Adults: 2, Children: 1, ChildAges: []int{5}}, "5 лет", true},
+		{"en с ребенком", i18n.EN, &TravelParty{Adults: 2, Children: 1, ChildAges: []int{5}}, "5 years", true},
+	}
+
+	for _, tt := range tests {
+		request := TravelRequest{Language: tt.lang, Party: tt.party, ChildAge: tt.childAge}
+		preview := request.ToClientPreview()
+
+		if got := strings.Contains(preview, escapeHTML(i18n.T(tt.lang, "card.child_age"))); got != tt.want {
+			t.Errorf("%s: возраст ребенка в карточке = %v, want %v", tt.name, got, tt.want)
+		}
+		if strings.Contains(preview, "Нет детей") {
+			t.Errorf("%s: в карточке осталась заглушка «Нет детей»", tt.name)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package questionnaire
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/models"
+	"strings"
+)
+
+//go:embed form.json
+var defaultForm []byte
+
+type InputType string
+
+const (
+	// InputText — ответ свободным текстом.
+	InputText InputType = "text"
+	// InputChoice — варианты ответа кнопками, свой ответ можно написать текстом.
+	InputChoice InputType = "choice"
+	// InputMultiChoice — несколько вариантов, отмечаемых кнопками, и кнопка «Готово».
+	InputMultiChoice InputType = "multichoice"
+	// InputTravelers — счетчики взрослых и детей на кнопках.
+	InputTravelers InputType = "travelers"
+	// InputChildAges — вопрос задается отдельно про каждого ребенка из состава туристов.
+	InputChildAges InputType = "child_ages"
+)
+
+// MultiChoiceSeparator разделяет отмеченные варианты в ответе на вопрос
+// с несколькими вариантами.
+const MultiChoiceSeparator = " + "
+
+// Form — описание анкеты: вступление и вопросы в порядке их задавания.
+// Тексты анкеты пишутся на языке по умолчанию, переводы на другие языки
+// задаются в Translations по коду языка.
+type Form struct {
+	Intro        string                     `json:"intro"`
+	Questions    []Question                 `json:"questions"`
+	Translations map[string]FormTranslation `json:"translations,omitempty"`
+}
+
+// FormTranslation — перевод вступления и вопросов анкеты по ключам вопросов.
+// Непереведенные тексты показываются на языке по умолчанию.
+type FormTranslation struct {
+	Intro     string                         `json:"intro,omitempty"`
+	Questions map[string]QuestionTranslation `json:"questions,omitempty"`
+}
+
+// QuestionTranslation — перевод вопроса. Варианты ответа переводятся
+// все сразу и в том же порядке, что и в самом вопросе.
+type QuestionTranslation struct {
+	Label   string     `json:"label,omitempty"`
+	Prompt  string     `json:"prompt,omitempty"`
+	Options [][]string `json:"options,omitempty"`
+}
+
+type Question struct {
+	Key       string     `json:"key"`
+	Label     string     `json:"label"`
+	Prompt    string     `json:"prompt"`
+	Type      InputType  `json:"type"`
+	Validator string     `json:"validator,omitempty"`
+	Options   [][]string `json:"options,omitempty"`
+	Condition *Condition `json:"condition,omitempty"`
+	// SkipValue записывается в ответ, если вопрос пропущен по условию.
+	SkipValue string `json:"skip_value,omitempty"`
+
+	translations map[string]QuestionTranslation
+}
+
+// Condition — условие, при котором вопрос задается: ответ на поле Field
+// содержит одну из подстрок ContainsAny (без учета регистра) или, если задан
+// HasChildren, среди туристов есть дети. Ответы записываются на языке клиента,
+// поэтому в анкете с переводами ContainsAny перечисляет варианты на всех языках.
+type Condition struct {
+	Field       string   `json:"field,omitempty"`
+	ContainsAny []string `json:"contains_any,omitempty"`
+	HasChildren bool     `json:"has_children,omitempty"`
+}
+
+// Load читает анкету из JSON-файла. Пустой путь означает встроенную анкету.
+func Load(path string) (*Form, error) {
+	data := defaultForm
+	if path != "" {
+		fileData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать анкету %s: %w", path, err)
+		}
+		data = fileData
+	}
+
+	var form Form
+	if err := json.Unmarshal(data, &form); err != nil {
+		return nil, fmt.Errorf("некорректный JSON анкеты: %w", err)
+	}
+
+	if err := form.validate(); err != nil {
+		return nil, err
+	}
+	if err := form.attachTranslations(); err != nil {
+		return nil, err
+	}
+
+	return &form, nil
+}
+
+func (f *Form) validate() error {
+	if len(f.Questions) == 0 {
+		return fmt.Errorf("анкета не содержит вопросов")
+	}
+
+	seen := make(map[string]bool)
+	for i, q := range f.Questions {
+		if q.Key == "" {
+			return fmt.Errorf("у вопроса №%d не задан key", i+1)
+		}
+		if seen[q.Key] {
+			return fmt.Errorf("ключ %q повторяется", q.Key)
+		}
+		if q.Prompt == "" {
+			return fmt.Errorf("у вопроса %q не задан prompt", q.Key)
+		}
+
+		switch q.Type {
+		case InputText, InputTravelers, InputChildAges:
+		case InputChoice, InputMultiChoice:
+			if len(q.Options) == 0 {
+				return fmt.Errorf("у вопроса %q с типом %s нет options", q.Key, q.Type)
+			}
+		default:
+			return fmt.Errorf("у вопроса %q неизвестный тип %q", q.Key, q.Type)
+		}
+
+		if q.Validator != "" {
+			if _, ok := validators[q.Validator]; !ok {
+				return fmt.Errorf("у вопроса %q неизвестный валидатор %q", q.Key, q.Validator)
+			}
+		}
+
+		if q.Condition != nil {
+			if i == 0 {
+				return fmt.Errorf("первый вопрос %q не может иметь условие", q.Key)
+			}
+			if q.Condition.Field != "" && !seen[q.Condition.Field] {
+				return fmt.Errorf("условие вопроса %q ссылается на поле %q, которое еще не задано", q.Key, q.Condition.Field)
+			}
+		}
+
+		seen[q.Key] = true
+	}
+
+	return nil
+}
+
+// attachTranslations проверяет переводы и раздает их вопросам.
+func (f *Form) attachTranslations() error {
+	for lang, translation := range f.Translations {
+		if !i18n.IsSupported(lang) {
+			return fmt.Errorf("перевод анкеты на неподдерживаемый язык %q", lang)
+		}
+
+		for key, qt := range translation.Questions {
+			q, ok := f.Question(key)
+			if !ok {
+				return fmt.Errorf("перевод %s ссылается на несуществующий вопрос %q", lang, key)
+			}
+			if qt.Options != nil && !sameShape(q.Options, qt.Options) {
+				return fmt.Errorf("варианты ответа вопроса %q в переводе %s не совпадают с исходными по числу", key, lang)
+			}
+
+			if q.translations == nil {
+				q.translations = make(map[string]QuestionTranslation)
+			}
+			q.translations[lang] = qt
+		}
+	}
+	return nil
+}
+
+func sameShape(a, b [][]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if len(a[i]) != len(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// IntroText возвращает вступление анкеты на языке lang.
+func (f *Form) IntroText(lang string) string {
+	if intro := f.Translations[lang].Intro; intro != "" {
+		return intro
+	}
+	return f.Intro
+}
+
+// LabelText возвращает короткое название вопроса на языке lang.
+func (q *Question) LabelText(lang string) string {
+	if label := q.translations[lang].Label; label != "" {
+		return label
+	}
+	return q.Label
+}
+
+// PromptText возвращает текст вопроса на языке lang.
+func (q *Question) PromptText(lang string) string {
+	if prompt := q.translations[lang].Prompt; prompt != "" {
+		return prompt
+	}
+	return q.Prompt
+}
+
+// OptionsText возвращает варианты ответа на языке lang для кнопок и
+// показа ответов клиенту и менеджеру.
+func (q *Question) OptionsText(lang string) [][]string {
+	if options := q.translations[lang].Options; options != nil {
+		return options
+	}
+	return q.Options
+}
+
+// First возвращает первый вопрос анкеты.
+func (f *Form) First() *Question {
+	return &f.Questions[0]
+}
+
+// Question ищет вопрос по ключу.
+func (f *Form) Question(key string) (*Question, bool) {
+	for i := range f.Questions {
+		if f.Questions[i].Key == key {
+			return &f.Questions[i], true
+		}
+	}
+	return nil, false
+}
+
+// Next возвращает вопрос, следующий за вопросом key, или nil, если анкета заполнена.
+// Вопросам, пропущенным по условию, в заявку записывается их SkipValue.
+func (f *Form) Next(key string, request *models.TravelRequest) *Question {
+	index := -1
+	for i := range f.Questions {
+		if f.Questions[i].Key == key {
+			index = i
+			break
+		}
+	}
+
+	for i := index + 1; i < len(f.Questions); i++ {
+		q := &f.Questions[i]
+		if q.Condition == nil || q.Condition.Matches(request) {
+			return q
+		}
+		request.SetField(q.Key, q.Label, q.SkipValue)
+	}
+
+	return nil
+}
+
+// NextUnanswered возвращает первый применимый вопрос без ответа или nil,
+// если ответы есть на все. Используется после правки отдельного ответа,
+// которая могла сделать применимыми ранее пропущенные вопросы.
+func (f *Form) NextUnanswered(request *models.TravelRequest) *Question {
+	for i := range f.Questions {
+		q := &f.Questions[i]
+		if q.Condition != nil && !q.Condition.Matches(request) {
+			request.SetField(q.Key, q.Label, q.SkipValue)
+			continue
+		}
+
+		answer := request.Field(q.Key)
+		if answer == "" || (q.Condition != nil && answer == q.SkipValue) {
+			return q
+		}
+	}
+	return nil
+}
+
+// Applicable возвращает вопросы, которые задаются при текущих ответах.
+func (f *Form) Applicable(request *models.TravelRequest) []*Question {
+	var questions []*Question
+	for i := range f.Questions {
+		q := &f.Questions[i]
+		if q.Condition == nil || q.Condition.Matches(request) {
+			questions = append(questions, q)
+		}
+	}
+	return questions
+}
+
+// Option возвращает вариант ответа по позиции кнопки на языке анкеты: в
+// заявку он записывается так независимо от языка клиента, чтобы по нему
+// работали условия вопросов и правила распределения заявок.
+func (q *Question) Option(row, col int) (string, bool) {
+	if row < 0 || row >= len(q.Options) || col < 0 || col >= len(q.Options[row]) {
+		return "", false
+	}
+	return q.Options[row][col], true
+}
+
+// AnswerText переводит записанный ответ на язык lang. Свой ответ клиента,
+// не совпадающий с вариантами, возвращается как есть.
+func (q *Question) AnswerText(lang, answer string) string {
+	return q.replaceOptions(answer, q.OptionsText(lang))
+}
+
+// CanonicalAnswer заменяет варианты, написанные клиентом текстом на любом
+// языке анкеты, вариантами на языке анкеты.
+func (q *Question) CanonicalAnswer(answer string) string {
+	return q.replaceOptions(answer, q.Options)
+}
+
+// AnswerText переводит ответ на вопрос key на язык lang.
+func (f *Form) AnswerText(key, lang, answer string) string {
+	q, ok := f.Question(key)
+	if !ok {
+		return answer
+	}
+	return q.AnswerText(lang, answer)
+}
+
+// replaceOptions заменяет варианты в ответе вариантами из options на тех же
+// позициях. Ответ на вопрос с несколькими вариантами заменяется, только если
+// все его части — варианты.
+func (q *Question) replaceOptions(answer string, options [][]string) string {
+	if len(q.Options) == 0 || answer == "" {
+		return answer
+	}
+	if row, col, ok := q.findOption(answer); ok {
+		return options[row][col]
+	}
+	if q.Type != InputMultiChoice {
+		return answer
+	}
+
+	parts := strings.Split(answer, MultiChoiceSeparator)
+	for i, part := range parts {
+		row, col, ok := q.findOption(part)
+		if !ok {
+			return answer
+		}
+		parts[i] = options[row][col]
+	}
+	return strings.Join(parts, MultiChoiceSeparator)
+}
+
+// findOption ищет позицию варианта ответа на языке анкеты или в переводах
+// без учета регистра.
+func (q *Question) findOption(text string) (row, col int, ok bool) {
+	text = strings.TrimSpace(text)
+	if row, col, ok := optionPosition(q.Options, text); ok {
+		return row, col, true
+	}
+	for _, qt := range q.translations {
+		if row, col, ok := optionPosition(qt.Options, text); ok {
+			return row, col, true
+		}
+	}
+	return 0, 0, false
+}
+
+func optionPosition(options [][]string, text string) (row, col int, ok bool) {
+	for i := range options {
+		for j, option := range options[i] {
+			if strings.EqualFold(option, text) {
+				return i, j, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func (c *Condition) Matches(request *models.TravelRequest) bool {
+	if c.HasChildren {
+		return request.Party != nil && request.Party.Children > 0
+	}
+
+	answer := strings.ToLower(request.Field(c.Field))
+	for _, substr := range c.ContainsAny {
+		if strings.Contains(answer, strings.ToLower(substr)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package questionnaire
+
+import (
+	"pumpkin_travel_tg_bot/i18n"
+	"testing"
+)
+
+func TestAnswerTranslation(t *testing.T) {
+	form, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	vacation, _ := form.Question("vacation_type")
+	meal, _ := form.Question("meal_plan")
+
+	canonical := []struct {
+		q      *Question
+		answer string
+		want   string
+	}{
+		{vacation, "beach", "Пляжный"},
+		{vacation, "Beach + With kids", "Пляжный + С детьми"},
+		{vacation, "Beach + на море", "Beach + на море"},
+		{meal, "Breakfast + dinner", "Завтрак + ужин"},
+		{meal, "только завтраки", "только завтраки"},
+	}
+	for _, tc := range canonical {
+		if got := tc.q.CanonicalAnswer(tc.answer); got != tc.want {
+			t.Errorf("CanonicalAnswer(%s, %q) = %q, want %q", tc.q.Key, tc.answer, got, tc.want)
+		}
+	}
+
+	translated := []struct {
+		key, lang, answer, want string
+	}{
+		{"vacation_type", i18n.EN, "Пляжный + Активный", "Beach + Active"},
+		{"meal_plan", i18n.EN, "Завтрак + ужин", "Breakfast + dinner"},
+		{"meal_plan", i18n.RU, "Завтрак + ужин", "Завтрак + ужин"},
+		// Заявки, записанные до хранения ответов на языке анкеты.
+		{"hotel_level", i18n.RU, "Any level", "Любой уровень"},
+		{"important_factors", i18n.EN, "Пляжный", "Пляжный"},
+	}
+	for _, tc := range translated {
+		if got := form.AnswerText(tc.key, tc.lang, tc.answer); got != tc.want {
+			t.Errorf("AnswerText(%s, %s, %q) = %q, want %q", tc.key, tc.lang, tc.answer, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package questionnaire
+
+import (
+	"errors"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/utils"
+	"time"
+)
+
+// Validator проверяет ответ на вопрос анкеты с учетом уже данных ответов.
+// Ошибка показывается клиенту, поэтому валидаторы возвращают *ValidationError
+// с текстом, который объясняет, как ответить правильно.
+type Validator func(answer string, request *models.TravelRequest) error
+
+// ValidationError — ошибка в ответе клиента: ключ текста в каталоге переводов
+// и значения для подстановки в него.
+type ValidationError struct {
+	Key  string
+	Args []interface{}
+}
+
+// localizedArg — значение, которое описывается на языке клиента, например окно дат.
+type localizedArg interface {
+	Text(lang string) string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message(i18n.Default)
+}
+
+// Message возвращает текст ошибки на языке lang.
+func (e *ValidationError) Message(lang string) string {
+	args := make([]interface{}, len(e.Args))
+	for i, arg := range e.Args {
+		if localized, ok := arg.(localizedArg); ok {
+			arg = localized.Text(lang)
+		}
+		args[i] = arg
+	}
+	return i18n.T(lang, e.Key, args...)
+}
+
+func validationError(key string, args ...interface{}) error {
+	return &ValidationError{Key: key, Args: args}
+}
+
+// validators — валидаторы, на которые можно сослаться из анкеты по имени.
+var validators = map[string]Validator{
+	"not_empty":    validateNotEmpty,
+	"budget":       validateBudget,
+	"countries":    validateCountries,
+	"travel_dates": validateTravelDates,
+	"duration":     validateDuration,
+	"travelers":    validateTravelers,
+	"child_age":    validateChildAge,
+}
+
+var errEmptyAnswer = validationError("validate.empty")
+
+// Validate проверяет ответ валидатором вопроса. Пустой ответ не принимается
+// ни на один вопрос.
+func (q *Question) Validate(answer string, request *models.TravelRequest) error {
+	if err := validateNotEmpty(answer, request); err != nil {
+		return err
+	}
+	if q.Validator == "" {
+		return nil
+	}
+	return validators[q.Validator](answer, request)
+}
+
+func validateNotEmpty(answer string, _ *models.TravelRequest) error {
+	if !utils.ValidateNotEmpty(answer) {
+		return errEmptyAnswer
+	}
+	return nil
+}
+
+func validateBudget(answer string, _ *models.TravelRequest) error {
+	if !utils.ValidateBudget(answer) {
+		return validationError("validate.budget")
+	}
+	return nil
+}
+
+func validateCountries(answer string, _ *models.TravelRequest) error {
+	if len(utils.ValidateCountries(answer)) == 0 && !utils.IsUndecidedDestination(answer) {
+		return validationError("validate.countries")
+	}
+	return nil
+}
+
+func validateTravelDates(answer string, request *models.TravelRequest) error {
+	now := RequestTime(request)
+	window, err := utils.ParseTravelDates(answer, now)
+	if errors.Is(err, utils.ErrNonexistentDate) {
+		return validationError("validate.nonexistent_date")
+	}
+	if window == nil {
+		return nil
+	}
+	if window.IsPast(now) {
+		return validationError("validate.past_dates")
+	}
+	// Даты правят после ответа о длительности: проверяем, что она по-прежнему помещается.
+	if request.Nights != nil && !request.Nights.FitsWindow(window) {
+		return durationMismatch(window, request.Nights)
+	}
+	return nil
+}
+
+func validateDuration(answer string, request *models.TravelRequest) error {
+	nights := utils.ParseDuration(answer)
+	if nights == nil || nights.FitsWindow(request.DateWindow) {
+		return nil
+	}
+	return durationMismatch(request.DateWindow, nights)
+}
+
+func durationMismatch(window *models.DateWindow, nights *models.NightsRange) error {
+	available := &models.NightsRange{Min: window.Days() - 1, Max: window.Days() - 1}
+	return validationError("validate.duration_mismatch", window, available, nights)
+}
+
+func validateTravelers(answer string, _ *models.TravelRequest) error {
+	party := utils.ParseTravelers(answer)
+	if party == nil {
+		return validationError("validate.travelers")
+	}
+	if party.Adults == 0 {
+		return validationError("validate.adults")
+	}
+	return nil
+}
+
+func validateChildAge(answer string, _ *models.TravelRequest) error {
+	age, ok := utils.ParseChildAge(answer)
+	if !ok {
+		return validationError("validate.child_age")
+	}
+	if age < 0 || age > 17 {
+		return validationError("validate.child_age_range")
+	}
+	return nil
+}
+
+// RequestTime возвращает момент, относительно которого разбираются даты
+// в ответах: время начала заполнения заявки.
+func RequestTime(request *models.TravelRequest) time.Time {
+	if request.CreatedAt.IsZero() {
+		return time.Now()
+	}
+	return request.CreatedAt
+}
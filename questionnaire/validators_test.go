@@ -0,0 +1,33 @@
+package questionnaire
+
+import (
+	"errors"
+	"pumpkin_travel_tg_bot/models"
+	"testing"
+	"time"
+)
+
+func TestValidateTravelDates(t *testing.T) {
+	request := &models.TravelRequest{CreatedAt: time.Date(2025, time.August, 15, 12, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		answer  string
+		wantKey string
+	}{
+		{"10–20 сентября", ""},
+		{"когда будут билеты", ""},
+		{"31 февраля", "validate.nonexistent_date"},
+		{"5.08", "validate.past_dates"},
+	}
+
+	for _, tt := range tests {
+		err := validateTravelDates(tt.answer, request)
+		var validation *ValidationError
+		switch {
+		case tt.wantKey == "" && err != nil:
+			t.Errorf("validateTravelDates(%q) = %v, want nil", tt.answer, err)
+		case tt.wantKey != "" && (!errors.As(err, &validation) || validation.Key != tt.wantKey):
+			t.Errorf("validateTravelDates(%q) = %v, want %s", tt.answer, err, tt.wantKey)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/models"
+	"strings"
+	"sync"
+)
+
+type Strategy string
+
+const (
+	// StrategyRoundRobin — подходящие менеджеры получают заявки по очереди.
+	StrategyRoundRobin Strategy = "round_robin"
+	// StrategyLeastLoaded — заявка достается менеджеру с наименьшим числом открытых заявок.
+	StrategyLeastLoaded Strategy = "least_loaded"
+)
+
+// Manager — менеджер, его чат и правила, по которым ему направляются заявки.
+// Незаданные правила не ограничивают выбор; менеджер без правил получает
+// заявки, которые не подошли ни одному специалисту.
+type Manager struct {
+	Name   string `json:"name"`
+	ChatID int64  `json:"chat_id"`
+	// Language — язык карточек заявок и сообщений бота в чате менеджера.
+	Language string `json:"language,omitempty"`
+
+	// Destinations — страны и курорты; подходит заявка, где упомянуто хотя бы одно.
+	Destinations []string `json:"destinations,omitempty"`
+	// VacationTypes — типы отдыха на языке анкеты; подходит заявка с хотя бы одним из них.
+	VacationTypes []string `json:"vacation_types,omitempty"`
+	// BudgetMin, BudgetMax и Currency — диапазон бюджета, с которым пересекается бюджет заявки.
+	BudgetMin int64  `json:"budget_min,omitempty"`
+	BudgetMax int64  `json:"budget_max,omitempty"`
+	Currency  string `json:"currency,omitempty"`
+}
+
+func (m *Manager) hasRules() bool {
+	return len(m.Destinations) > 0 || len(m.VacationTypes) > 0 ||
+		m.BudgetMin != 0 || m.BudgetMax != 0 || m.Currency != ""
+}
+
+// Matches сообщает, подходит ли заявка под все правила менеджера.
+func (m *Manager) Matches(request *models.TravelRequest) bool {
+	if len(m.Destinations) > 0 && !containsAnyFold(request.Destinations, m.Destinations) {
+		return false
+	}
+	if len(m.VacationTypes) > 0 && !containsAnyFold([]string{request.VacationType}, m.VacationTypes) {
+		return false
+	}
+	if m.BudgetMin != 0 || m.BudgetMax != 0 || m.Currency != "" {
+		budget := request.BudgetRange
+		if budget == nil || (m.Currency != "" && budget.Currency != m.Currency) {
+			return false
+		}
+		if !budget.Overlaps(m.BudgetMin, m.BudgetMax) {
+			return false
+		}
+	}
+	return true
+}
+
+// Router выбирает менеджера для заявки. Если не подошел ни один менеджер,
+// заявка уходит в резервный чат.
+type Router struct {
+	Strategy Strategy  `json:"strategy"`
+	Managers []Manager `json:"managers"`
+
+	fallback Manager
+
+	mu   sync.Mutex
+	next int
+}
+
+// Load читает менеджеров и правила из JSON-файла. Пустой путь означает, что
+// все заявки уходят в резервный чат fallbackChatID. defaultLanguage — язык
+// резервного чата и менеджеров, у которых язык не указан.
+func Load(path string, fallbackChatID int64, defaultLanguage string) (*Router, error) {
+	router := &Router{Strategy: StrategyRoundRobin}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать список менеджеров %s: %w", path, err)
+		}
+		if err := json.Unmarshal(data, router); err != nil {
+			return nil, fmt.Errorf("некорректный JSON списка менеджеров: %w", err)
+		}
+	}
+
+	if err := router.validate(); err != nil {
+		return nil, err
+	}
+
+	for i := range router.Managers {
+		if router.Managers[i].Language == "" {
+			router.Managers[i].Language = defaultLanguage
+		}
+	}
+	router.fallback = Manager{ChatID: fallbackChatID, Language: defaultLanguage}
+	return router, nil
+}
+
+func (r *Router) validate() error {
+	switch r.Strategy {
+	case "":
+		r.Strategy = StrategyRoundRobin
+	case StrategyRoundRobin, StrategyLeastLoaded:
+	default:
+		return fmt.Errorf("неизвестная стратегия распределения заявок %q", r.Strategy)
+	}
+
+	for i, manager := range r.Managers {
+		if manager.ChatID == 0 {
+			return fmt.Errorf("у менеджера %d (%s) не задан chat_id", i+1, manager.Name)
+		}
+		if manager.BudgetMin != 0 && manager.BudgetMax != 0 && manager.BudgetMin > manager.BudgetMax {
+			return fmt.Errorf("у менеджера %s budget_min больше budget_max", manager.Name)
+		}
+		if manager.Language != "" && !i18n.IsSupported(manager.Language) {
+			return fmt.Errorf("у менеджера %s неподдерживаемый язык %q", manager.Name, manager.Language)
+		}
+	}
+	return nil
+}
+
+// Route выбирает менеджера для заявки: сначала среди специалистов, чьи правила
+// подходят под заявку, затем среди менеджеров без правил. openLeads возвращает
+// число открытых заявок менеджера и нужен для стратегии least_loaded; между
+// одинаково загруженными менеджерами заявки распределяются по очереди.
+func (r *Router) Route(request *models.TravelRequest, openLeads func(manager Manager) int) Manager {
+	var specialists, generalists []Manager
+	for _, manager := range r.Managers {
+		switch {
+		case !manager.hasRules():
+			generalists = append(generalists, manager)
+		case manager.Matches(request):
+			specialists = append(specialists, manager)
+		}
+	}
+
+	candidates := specialists
+	if len(candidates) == 0 {
+		candidates = generalists
+	}
+	if len(candidates) == 0 {
+		return r.fallback
+	}
+
+	if r.Strategy == StrategyLeastLoaded && openLeads != nil {
+		candidates = leastLoaded(candidates, openLeads)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	manager := candidates[r.next%len(candidates)]
+	r.next++
+	return manager
+}
+
+// leastLoaded оставляет менеджеров с наименьшим числом открытых заявок.
+func leastLoaded(candidates []Manager, openLeads func(manager Manager) int) []Manager {
+	var best []Manager
+	bestLoad := 0
+	for _, manager := range candidates {
+		load := openLeads(manager)
+		switch {
+		case len(best) == 0 || load < bestLoad:
+			best, bestLoad = []Manager{manager}, load
+		case load == bestLoad:
+			best = append(best, manager)
+		}
+	}
+	return best
+}
+
+// Fallback возвращает резервный чат для заявок без подходящего менеджера.
+func (r *Router) Fallback() Manager {
+	return r.fallback
+}
+
+// IsManagerChat сообщает, что в чат доставляются заявки: это чат одного
+// из менеджеров или резервный чат.
+func (r *Router) IsManagerChat(chatID int64) bool {
+	if chatID == 0 {
+		return false
+	}
+	if chatID == r.fallback.ChatID {
+		return true
+	}
+	for _, manager := range r.Managers {
+		if manager.ChatID == chatID {
+			return true
+		}
+	}
+	return false
+}
+
+// Language возвращает язык чата менеджера. Если в одном чате несколько
+// менеджеров, используется язык первого из них.
+func (r *Router) Language(chatID int64) string {
+	for _, manager := range r.Managers {
+		if manager.ChatID == chatID {
+			return manager.Language
+		}
+	}
+	return r.fallback.Language
+}
+
+func containsAnyFold(values, patterns []string) bool {
+	for _, value := range values {
+		lower := strings.ToLower(value)
+		for _, pattern := range patterns {
+			if pattern != "" && strings.Contains(lower, strings.ToLower(pattern)) {
+				return true
+			}
+		}
+	}
+	return false
+}
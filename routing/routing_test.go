@@ -0,0 +1,71 @@
+package routing
+
+import (
+	"pumpkin_travel_tg_bot/models"
+	"testing"
+)
+
+func TestRouteLeastLoadedCountsLeadsPerManager(t *testing.T) {
+	router := &Router{
+		Strategy: StrategyLeastLoaded,
+		Managers: []Manager{
+			{Name: "Анна", ChatID: 1},
+			{Name: "Борис", ChatID: 1},
+			{Name: "Вера", ChatID: 2},
+		},
+	}
+	load := map[string]int{"Анна": 3, "Борис": 0, "Вера": 1}
+	openLeads := func(manager Manager) int { return load[manager.Name] }
+
+	got := router.Route(&models.TravelRequest{}, openLeads)
+	if got.Name != "Борис" {
+		t.Fatalf("Route() = %s, want Борис: у него меньше всего открытых заявок", got.Name)
+	}
+}
+
+func TestRouteLeastLoadedBreaksTiesRoundRobin(t *testing.T) {
+	router := &Router{
+		Strategy: StrategyLeastLoaded,
+		Managers: []Manager{
+			{Name: "Анна", ChatID: 1},
+			{Name: "Борис", ChatID: 1},
+			{Name: "Вера", ChatID: 2},
+		},
+	}
+	load := map[string]int{"Анна": 2, "Борис": 2, "Вера": 5}
+	openLeads := func(manager Manager) int { return load[manager.Name] }
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, router.Route(&models.TravelRequest{}, openLeads).Name)
+	}
+	want := []string{"Анна", "Борис", "Анна", "Борис"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Route() по очереди = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRoutePrefersSpecialists(t *testing.T) {
+	router := &Router{
+		Strategy: StrategyRoundRobin,
+		Managers: []Manager{
+			{Name: "Общий", ChatID: 1},
+			{Name: "Турция", ChatID: 2, Destinations: []string{"Турция"}},
+		},
+		fallback: Manager{ChatID: 100},
+	}
+
+	if got := router.Route(&models.TravelRequest{Destinations: []string{"Турция"}}, nil); got.Name != "Турция" {
+		t.Errorf("Route(Турция) = %s, want Турция", got.Name)
+	}
+	if got := router.Route(&models.TravelRequest{Destinations: []string{"Египет"}}, nil); got.Name != "Общий" {
+		t.Errorf("Route(Египет) = %s, want Общий", got.Name)
+	}
+
+	router.Managers = router.Managers[1:]
+	if got := router.Route(&models.TravelRequest{Destinations: []string{"Египет"}}, nil); got.ChatID != 100 {
+		t.Errorf("Route(Египет) без общих менеджеров = %d, want резервный чат 100", got.ChatID)
+	}
+}
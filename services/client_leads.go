@@ -0,0 +1,88 @@
+package services
+
+import (
+	"fmt"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/storage"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// ClientLeads возвращает заявки клиента от новых к старым.
+func (fs *FormService) ClientLeads(userID int64) ([]models.Lead, error) {
+	leads, err := fs.leads.List(storage.LeadFilter{UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать заявки клиента: %w", err)
+	}
+
+	for i, j := 0, len(leads)-1; i < j; i, j = i+1, j-1 {
+		leads[i], leads[j] = leads[j], leads[i]
+	}
+	return leads, nil
+}
+
+// ClientLead возвращает заявку клиента по номеру. Чужие заявки не выдаются:
+// для них, как и для несуществующих, возвращается ErrLeadNotFound.
+func (fs *FormService) ClientLead(userID, number int64) (*models.Lead, error) {
+	lead, found, err := fs.leads.Get(number)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать заявку №%d: %w", number, err)
+	}
+	if !found || lead.UserInfo.ID != userID {
+		return nil, ErrLeadNotFound
+	}
+	return lead, nil
+}
+
+// CancelLead отменяет заявку по просьбе клиента и предупреждает менеджера,
+// если заявка уже была ему доставлена. Сообщения менеджеру отправляются
+// после снятия leadMu.
+func (fs *FormService) CancelLead(userID, number int64) (*models.Lead, error) {
+	lead, err := fs.saveCancellation(userID, number)
+	if err != nil {
+		return lead, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"lead_number": lead.Number,
+		"user_id":     userID,
+	}).Info("Клиент отменил заявку")
+
+	if lead.Delivery.MessageID != 0 {
+		fs.refreshLeadCard(lead)
+
+		msg := tgbotapi.NewMessage(lead.Delivery.ChatID,
+			i18n.T(fs.router.Language(lead.Delivery.ChatID), "manager.client_cancelled", lead.Number))
+		msg.ReplyToMessageID = lead.Delivery.MessageID
+		msg.AllowSendingWithoutReply = true
+		if _, err := fs.bot.Send(msg); err != nil {
+			logrus.WithError(err).WithField("lead_number", lead.Number).Warn("Не удалось сообщить менеджеру об отмене заявки")
+		}
+	}
+
+	return lead, nil
+}
+
+func (fs *FormService) saveCancellation(userID, number int64) (*models.Lead, error) {
+	fs.leadMu.Lock()
+	defer fs.leadMu.Unlock()
+
+	lead, err := fs.ClientLead(userID, number)
+	if err != nil {
+		return nil, err
+	}
+	if !lead.Status.CanChangeTo(models.LeadStatusCancelled) {
+		return lead, ErrLeadStatusChange
+	}
+
+	lead.Status = models.LeadStatusCancelled
+	lead.HandledBy = &lead.UserInfo
+	lead.HandledAt = time.Now()
+	if err := fs.leads.Update(*lead); err != nil {
+		return nil, fmt.Errorf("не удалось отменить заявку №%d: %w", number, err)
+	}
+	return lead, nil
+}
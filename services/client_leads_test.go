@@ -0,0 +1,33 @@
+package services
+
+import (
+	"errors"
+	"pumpkin_travel_tg_bot/models"
+	"testing"
+)
+
+func TestCancelLeadCallsTelegramWithoutLeadLock(t *testing.T) {
+	const chatID = 100
+	fs, client := newTestFormServiceWithBot(t, chatID)
+	lead := deliveredLead(t, fs, chatID)
+	locked := countLockedRequests(fs, client)
+
+	cancelled, err := fs.CancelLead(lead.UserInfo.ID, lead.Number)
+	if err != nil {
+		t.Fatalf("CancelLead: %v", err)
+	}
+	if cancelled.Status != models.LeadStatusCancelled {
+		t.Errorf("Status = %s, want %s", cancelled.Status, models.LeadStatusCancelled)
+	}
+	if sent := client.Sent(); len(sent) != 2 {
+		t.Errorf("запросов к Telegram = %d, want 2: карточка и сообщение менеджеру", len(sent))
+	}
+	if n := locked.Load(); n != 0 {
+		t.Errorf("%d запросов к Telegram сделано под leadMu", n)
+	}
+
+	// Чужую заявку отменить нельзя.
+	if _, err := fs.CancelLead(lead.UserInfo.ID+1, lead.Number); !errors.Is(err, ErrLeadNotFound) {
+		t.Errorf("CancelLead чужой заявки = %v, want ErrLeadNotFound", err)
+	}
+}
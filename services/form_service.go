@@ -2,36 +2,126 @@ package services
 
 import (
 	"fmt"
-	"pumpkin_travel_tg_bot/config"
 	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/routing"
+	"pumpkin_travel_tg_bot/storage"
+	"sync"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/sirupsen/logrus"
 )
 
 type FormService struct {
-	bot *tgbotapi.BotAPI
+	bot    *tgbotapi.BotAPI
+	leads  storage.LeadRepository
+	router *routing.Router
+	topics storage.TopicStore
+	limits SubmitLimits
+
+	// submitMu не дает двум одновременным подтверждениям обойти лимиты отправки.
+	// Держится только на время проверки и сохранения заявки, без сетевых запросов.
+	submitMu sync.Mutex
+	// leadMu упорядочивает изменения заявок: смену статуса, отмену клиентом,
+	// переписку и запись результата доставки из очереди повторов.
+	leadMu sync.Mutex
+	// topicMu не дает создать две общие темы для одного направления.
+	topicMu sync.Mutex
 }
 
-func NewFormService(bot *tgbotapi.BotAPI) *FormService {
-	return &FormService{bot: bot}
+func NewFormService(
+	bot *tgbotapi.BotAPI,
+	leads storage.LeadRepository,
+	router *routing.Router,
+	topics storage.TopicStore,
+	limits SubmitLimits,
+) *FormService {
+	return &FormService{bot: bot, leads: leads, router: router, topics: topics, limits: limits}
 }
 
-func (fs *FormService) SendToManager(request models.TravelRequest, userInfo models.UserInfo) error {
-	if config.AppConfig.ManagerChatID == 0 {
-		return fmt.Errorf("MANAGER_CHAT_ID не задан")
+// SubmitRequest сохраняет подтвержденную заявку в базу и отправляет ее менеджеру.
+// Заявка не принимается, если клиент превысил лимит отправки (ErrSubmitRateLimited)
+// или уже отправил такую же (ErrDuplicateSubmission, вместе с прежней заявкой).
+// Неудачная отправка ошибкой не считается: заявка остается в очереди на повтор,
+// см. lead.Delivery.
+func (fs *FormService) SubmitRequest(request models.TravelRequest, userInfo models.UserInfo) (*models.Lead, error) {
+	lead, err := fs.createLead(request, userInfo)
+	if err != nil {
+		return lead, err
 	}
 
-	messageText := request.ToFormattedString(userInfo)
+	manager := fs.router.Route(&lead.Request, fs.openLeadCount)
+	lead.AssignedChatID = manager.ChatID
+	lead.AssignedTo = manager.Name
+
+	logrus.WithFields(logrus.Fields{
+		"lead_number": lead.Number,
+		"user_id":     userInfo.ID,
+		"manager":     manager.Name,
+	}).Info("Заявка сохранена")
+
+	fs.leadMu.Lock()
+	err = fs.leads.Update(*lead)
+	fs.leadMu.Unlock()
+	if err != nil {
+		logrus.WithError(err).WithField("lead_number", lead.Number).Error("Ошибка сохранения назначения менеджера")
+	}
+
+	fs.deliverLead(lead)
+
+	return lead, nil
+}
+
+// SendToManager отправляет карточку заявки без сохранения в резервный чат менеджеров.
+func (fs *FormService) SendToManager(request models.TravelRequest, userInfo models.UserInfo) error {
+	chatID := fs.router.Fallback().ChatID
+	_, err := fs.sendToManager(chatID, 0, request.ToFormattedString(userInfo, fs.router.Language(chatID)), nil)
+	return err
+}
 
-	msg := tgbotapi.NewMessage(config.AppConfig.ManagerChatID, messageText)
-	msg.ParseMode = "HTML"
+// ManagerLanguage возвращает язык сообщений бота в чате менеджера.
+func (fs *FormService) ManagerLanguage(chatID int64) string {
+	return fs.router.Language(chatID)
+}
 
-	if _, err := fs.bot.Send(msg); err != nil {
+// openLeadCount возвращает число незавершенных заявок, назначенных менеджеру.
+// Менеджеры без имени в общем чате считаются вместе.
+func (fs *FormService) openLeadCount(manager routing.Manager) int {
+	leads, err := fs.leads.List(storage.LeadFilter{
+		AssignedChatID: manager.ChatID,
+		AssignedTo:     manager.Name,
+		Open:           true,
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка подсчета открытых заявок менеджера")
+		return 0
+	}
+	return len(leads)
+}
+
+// sendToManager отправляет сообщение в чат менеджера, а если задан threadID —
+// в тему форума этого чата.
+func (fs *FormService) sendToManager(chatID int64, threadID int, messageText string, keyboard *tgbotapi.InlineKeyboardMarkup) (tgbotapi.Message, error) {
+	if chatID == 0 {
+		return tgbotapi.Message{}, fmt.Errorf("MANAGER_CHAT_ID не задан")
+	}
+
+	var sent tgbotapi.Message
+	var err error
+	if threadID != 0 {
+		sent, err = fs.sendToTopic(chatID, threadID, messageText, keyboard)
+	} else {
+		msg := tgbotapi.NewMessage(chatID, messageText)
+		msg.ParseMode = "HTML"
+		if keyboard != nil {
+			msg.ReplyMarkup = keyboard
+		}
+		sent, err = fs.bot.Send(msg)
+	}
+	if err != nil {
 		logrus.WithError(err).Error("Ошибка при отправке заявки менеджеру")
-		return err
+		return tgbotapi.Message{}, err
 	}
 
 	logrus.Info("✅ Заявка успешно отправлена менеджеру")
-	return nil
+	return sent, nil
 }
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/storage"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// LeadCallbackPrefix — префикс кнопок действий менеджера на карточке заявки:
+// lead_<действие>_<номер заявки>.
+const LeadCallbackPrefix = "lead_"
+
+var (
+	ErrLeadNotFound     = errors.New("заявка не найдена")
+	ErrLeadStatusChange = errors.New("статус заявки нельзя изменить")
+)
+
+// leadActions — кнопки карточки в порядке вывода и статусы, в которые они переводят заявку.
+// Надпись на кнопке берется из каталога переводов по ключу lead.action.<name>.
+var leadActions = []struct {
+	name   string
+	status models.LeadStatus
+}{
+	{"take", models.LeadStatusInProgress},
+	{"contact", models.LeadStatusContacted},
+	{"close", models.LeadStatusClosed},
+	{"reject", models.LeadStatusRejected},
+}
+
+// notifiedStatuses — статусы, о которых клиенту сообщается сообщением notify.<статус>.
+var notifiedStatuses = map[models.LeadStatus]bool{
+	models.LeadStatusInProgress: true,
+	models.LeadStatusContacted:  true,
+	models.LeadStatusClosed:     true,
+	models.LeadStatusRejected:   true,
+}
+
+// ParseLeadCallback разбирает нажатую менеджером кнопку карточки заявки.
+func ParseLeadCallback(data string) (number int64, status models.LeadStatus, ok bool) {
+	action, numberText, found := strings.Cut(strings.TrimPrefix(data, LeadCallbackPrefix), "_")
+	if !found {
+		return 0, "", false
+	}
+
+	number, err := strconv.ParseInt(numberText, 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+
+	for _, a := range leadActions {
+		if a.name == action {
+			return number, a.status, true
+		}
+	}
+	return 0, "", false
+}
+
+// leadActionsKeyboard — кнопки на языке lang, доступные для заявки в ее текущем
+// статусе. У завершенной заявки кнопок нет.
+func leadActionsKeyboard(lead *models.Lead, lang string) *tgbotapi.InlineKeyboardMarkup {
+	var buttons []tgbotapi.InlineKeyboardButton
+	for _, a := range leadActions {
+		if lead.Status.CanChangeTo(a.status) {
+			buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "lead.action."+a.name),
+				fmt.Sprintf("%s%s_%d", LeadCallbackPrefix, a.name, lead.Number)))
+		}
+	}
+	if len(buttons) == 0 {
+		return nil
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(buttons); i += 2 {
+		rows = append(rows, buttons[i:min(i+2, len(buttons))])
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &keyboard
+}
+
+// ChangeLeadStatus переводит заявку в новый статус по кнопке менеджера в чате chatID,
+// обновляет карточку заявки и сообщает клиенту о смене статуса. Под leadMu
+// только сохраняется статус: запросы к Telegram идут уже без блокировки.
+func (fs *FormService) ChangeLeadStatus(number int64, status models.LeadStatus, chatID int64, manager models.UserInfo) (*models.Lead, error) {
+	lead, err := fs.saveLeadStatus(number, status, chatID, manager)
+	if err != nil {
+		return lead, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"lead_number": lead.Number,
+		"status":      lead.Status,
+		"manager_id":  manager.ID,
+	}).Info("Статус заявки изменен")
+
+	fs.refreshLeadCard(lead)
+	fs.notifyClient(lead)
+
+	return lead, nil
+}
+
+func (fs *FormService) saveLeadStatus(number int64, status models.LeadStatus, chatID int64, manager models.UserInfo) (*models.Lead, error) {
+	fs.leadMu.Lock()
+	defer fs.leadMu.Unlock()
+
+	lead, found, err := fs.leads.Get(number)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать заявку №%d: %w", number, err)
+	}
+	// Кнопки действуют только на карточке заявки в чате, куда она была доставлена.
+	if !found || lead.Delivery.ChatID != chatID {
+		return nil, ErrLeadNotFound
+	}
+	if !lead.Status.CanChangeTo(status) {
+		return lead, ErrLeadStatusChange
+	}
+
+	lead.Status = status
+	lead.HandledBy = &manager
+	lead.HandledAt = time.Now()
+	if err := fs.leads.Update(*lead); err != nil {
+		return nil, fmt.Errorf("не удалось сохранить статус заявки №%d: %w", number, err)
+	}
+	return lead, nil
+}
+
+// ManagerLeads возвращает незавершенные заявки чата менеджеров от больших бюджетов
+// к меньшим. Резервный чат видит заявки всех менеджеров. Если задан budget,
+// остаются заявки, бюджет которых пересекается с ним.
+func (fs *FormService) ManagerLeads(chatID int64, budget *models.BudgetRange) ([]models.Lead, error) {
+	filter := storage.LeadFilter{Open: true, SortByBudget: true}
+	if chatID != fs.router.Fallback().ChatID {
+		filter.AssignedChatID = chatID
+	}
+	if budget != nil {
+		filter.BudgetMin, filter.BudgetMax, filter.Currency = budget.Min, budget.Max, budget.Currency
+	}
+
+	leads, err := fs.leads.List(filter)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать заявки чата менеджеров: %w", err)
+	}
+	return leads, nil
+}
+
+// refreshLeadCard показывает на карточке заявки новый статус и оставшиеся кнопки.
+func (fs *FormService) refreshLeadCard(lead *models.Lead) {
+	lang := fs.router.Language(lead.Delivery.ChatID)
+	edit := tgbotapi.NewEditMessageText(lead.Delivery.ChatID, lead.Delivery.MessageID, lead.ToFormattedString(lang))
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = leadActionsKeyboard(lead, lang)
+
+	if _, err := fs.bot.Request(edit); err != nil {
+		logrus.WithError(err).WithField("lead_number", lead.Number).Error("Ошибка обновления карточки заявки")
+	}
+}
+
+// notifyClient сообщает клиенту о новом статусе заявки на языке, на котором он ее заполнял.
+func (fs *FormService) notifyClient(lead *models.Lead) {
+	if !notifiedStatuses[lead.Status] {
+		return
+	}
+
+	text := i18n.T(lead.Request.Language, "notify."+string(lead.Status), lead.Number)
+	msg := tgbotapi.NewMessage(lead.UserInfo.ID, text)
+	msg.ParseMode = "HTML"
+	if _, err := fs.bot.Send(msg); err != nil {
+		logrus.WithError(err).WithField("lead_number", lead.Number).Warn("Не удалось сообщить клиенту о смене статуса заявки")
+	}
+}
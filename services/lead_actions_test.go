@@ -0,0 +1,146 @@
+package services
+
+import (
+	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/routing"
+	"pumpkin_travel_tg_bot/utils"
+	"sync/atomic"
+	"testing"
+)
+
+func TestManagerLeadsFiltersAndSortsByBudget(t *testing.T) {
+	const fallbackChatID, managerChatID = 100, 200
+
+	fs := newTestFormService(t, SubmitLimits{})
+	router, err := routing.Load("", fallbackChatID, "ru")
+	if err != nil {
+		t.Fatalf("routing.Load: %v", err)
+	}
+	fs.router = router
+
+	leads := []struct {
+		budget string
+		chatID int64
+		status models.LeadStatus
+	}{
+		{"до 100 тыс", managerChatID, models.LeadStatusNew},
+		{"300-400 тыс", managerChatID, models.LeadStatusInProgress},
+		{"200 тыс", fallbackChatID, models.LeadStatusNew},
+		{"500 тыс", managerChatID, models.LeadStatusClosed},
+		{"$3,000", managerChatID, models.LeadStatusNew},
+	}
+	for _, l := range leads {
+		lead, err := fs.leads.Create(models.TravelRequest{Budget: l.budget, BudgetRange: utils.ParseBudget(l.budget)}, models.UserInfo{ID: 1})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		lead.AssignedChatID = l.chatID
+		lead.Status = l.status
+		if err := fs.leads.Update(*lead); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		chatID int64
+		budget string
+		want   []string
+	}{
+		{"чат менеджера", managerChatID, "", []string{"300-400 тыс", "до 100 тыс", "$3,000"}},
+		{"резервный чат видит все", fallbackChatID, "", []string{"300-400 тыс", "200 тыс", "до 100 тыс", "$3,000"}},
+		{"фильтр по бюджету", fallbackChatID, "от 150 до 250 тыс", []string{"200 тыс"}},
+		{"фильтр по валюте", managerChatID, "до 5000 $", []string{"$3,000"}},
+	}
+	for _, tt := range tests {
+		var budget *models.BudgetRange
+		if tt.budget != "" {
+			budget = utils.ParseBudget(tt.budget)
+		}
+		got, err := fs.ManagerLeads(tt.chatID, budget)
+		if err != nil {
+			t.Fatalf("%s: ManagerLeads: %v", tt.name, err)
+		}
+
+		var budgets []string
+		for _, lead := range got {
+			budgets = append(budgets, lead.Request.Budget)
+		}
+		if len(budgets) != len(tt.want) {
+			t.Errorf("%s: ManagerLeads = %q, want %q", tt.name, budgets, tt.want)
+			continue
+		}
+		for i := range budgets {
+			if budgets[i] != tt.want[i] {
+				t.Errorf("%s: ManagerLeads = %q, want %q", tt.name, budgets, tt.want)
+				break
+			}
+		}
+	}
+}
+
+// newTestFormServiceWithBot — сервис с поддельным Bot API и резервным чатом fallbackChatID.
+func newTestFormServiceWithBot(t *testing.T, fallbackChatID int64) (*FormService, *fakeTelegram) {
+	t.Helper()
+
+	fs := newTestFormService(t, SubmitLimits{})
+	router, err := routing.Load("", fallbackChatID, "ru")
+	if err != nil {
+		t.Fatalf("routing.Load: %v", err)
+	}
+	fs.router = router
+
+	bot, client := newFakeBot(t)
+	fs.bot = bot
+	return fs, client
+}
+
+// deliveredLead сохраняет заявку клиента 1, доставленную в чат chatID.
+func deliveredLead(t *testing.T, fs *FormService, chatID int64) *models.Lead {
+	t.Helper()
+
+	lead, err := fs.leads.Create(models.TravelRequest{Destination: "Турция", Language: "ru"}, models.UserInfo{ID: 1})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	lead.AssignedChatID = chatID
+	lead.Delivery = models.LeadDelivery{Delivered: true, ChatID: chatID, MessageID: 10}
+	if err := fs.leads.Update(*lead); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	return lead
+}
+
+// countLockedRequests считает запросы к Telegram, сделанные под leadMu.
+func countLockedRequests(fs *FormService, client *fakeTelegram) *atomic.Int32 {
+	var locked atomic.Int32
+	client.onRequest = func(string) {
+		if fs.leadMu.TryLock() {
+			fs.leadMu.Unlock()
+			return
+		}
+		locked.Add(1)
+	}
+	return &locked
+}
+
+func TestChangeLeadStatusCallsTelegramWithoutLeadLock(t *testing.T) {
+	const chatID = 100
+	fs, client := newTestFormServiceWithBot(t, chatID)
+	lead := deliveredLead(t, fs, chatID)
+	locked := countLockedRequests(fs, client)
+
+	updated, err := fs.ChangeLeadStatus(lead.Number, models.LeadStatusInProgress, chatID, models.UserInfo{ID: 7})
+	if err != nil {
+		t.Fatalf("ChangeLeadStatus: %v", err)
+	}
+	if updated.Status != models.LeadStatusInProgress {
+		t.Errorf("Status = %s, want %s", updated.Status, models.LeadStatusInProgress)
+	}
+	if sent := client.Sent(); len(sent) != 2 {
+		t.Errorf("запросов к Telegram = %d, want 2: карточка и уведомление клиента", len(sent))
+	}
+	if n := locked.Load(); n != 0 {
+		t.Errorf("%d запросов к Telegram сделано под leadMu", n)
+	}
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"errors"
+	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/storage"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	retryBaseDelay = 10 * time.Second
+	retryMaxDelay  = 30 * time.Minute
+
+	// firstAttemptGrace — сколько ждать первую синхронную попытку отправки,
+	// прежде чем считать, что процесс упал и заявку нужно подхватить из очереди.
+	firstAttemptGrace = time.Minute
+)
+
+// RunOutbox периодически повторяет доставку заявок, которые не удалось
+// отправить менеджеру, пока не будет закрыт канал stop.
+func (fs *FormService) RunOutbox(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			fs.RetryPending()
+		}
+	}
+}
+
+// RetryPending отправляет все недоставленные заявки, у которых подошло время повтора.
+func (fs *FormService) RetryPending() {
+	// Отмененные клиентом до доставки заявки менеджеру не отправляются.
+	leads, err := fs.leads.List(storage.LeadFilter{Undelivered: true, Open: true})
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка чтения очереди недоставленных заявок")
+		return
+	}
+
+	now := time.Now()
+	for i := range leads {
+		if deliveryDue(leads[i], now) {
+			fs.deliverLead(&leads[i])
+		}
+	}
+}
+
+// deliverLead отправляет карточку заявки менеджеру и записывает результат попытки.
+// При ошибке следующая попытка планируется с экспоненциальной задержкой. Заявка
+// перечитывается из базы, а записывается только состояние доставки: пока карточка
+// отправлялась, клиент мог отменить заявку, а менеджер — сменить ее статус.
+func (fs *FormService) deliverLead(lead *models.Lead) {
+	current, found, err := fs.leads.Get(lead.Number)
+	if err != nil || !found {
+		logrus.WithError(err).WithField("lead_number", lead.Number).Error("Ошибка чтения заявки перед отправкой менеджеру")
+		return
+	}
+	*lead = *current
+	if lead.Status.IsFinal() {
+		logrus.WithFields(logrus.Fields{
+			"lead_number": lead.Number,
+			"status":      lead.Status,
+		}).Info("Заявка завершена до доставки и менеджеру не отправляется")
+		return
+	}
+
+	chatID := lead.AssignedChatID
+	if chatID == 0 {
+		chatID = fs.router.Fallback().ChatID
+	}
+	lang := fs.router.Language(chatID)
+	threadID := fs.leadTopic(chatID, lead)
+	sent, err := fs.sendToManager(chatID, threadID, lead.ToFormattedString(lang), leadActionsKeyboard(lead, lang))
+
+	delivery := lead.Delivery
+	delivery.Attempts++
+
+	if err == nil {
+		delivery.Delivered = true
+		delivery.DeliveredAt = time.Now()
+		delivery.NextAttempt = time.Time{}
+		delivery.LastError = ""
+		delivery.ChatID = sent.Chat.ID
+		delivery.MessageID = sent.MessageID
+	} else {
+		if threadID != 0 && isTopicMissing(err) {
+			fs.forgetTopic(chatID, &lead.Request, &delivery)
+		}
+
+		delay := retryDelay(delivery.Attempts, err)
+		delivery.NextAttempt = time.Now().Add(delay)
+		delivery.LastError = err.Error()
+
+		logrus.WithFields(logrus.Fields{
+			"lead_number": lead.Number,
+			"attempt":     delivery.Attempts,
+			"retry_in":    delay.String(),
+		}).WithError(err).Warn("Заявка поставлена в очередь на повторную отправку")
+	}
+
+	fs.leadMu.Lock()
+	updated, err := fs.leads.UpdateDelivery(lead.Number, delivery)
+	fs.leadMu.Unlock()
+	if err != nil {
+		logrus.WithError(err).WithField("lead_number", lead.Number).Error("Ошибка сохранения статуса доставки заявки")
+		lead.Delivery = delivery
+		return
+	}
+	*lead = *updated
+
+	// Заявку отменили, пока карточка отправлялась: показываем менеджеру актуальный статус.
+	if lead.Delivery.Delivered && lead.Status.IsFinal() {
+		fs.refreshLeadCard(lead)
+	}
+}
+
+func deliveryDue(lead models.Lead, now time.Time) bool {
+	if lead.Delivery.NextAttempt.IsZero() {
+		return now.Sub(lead.CreatedAt) > firstAttemptGrace
+	}
+	return !now.Before(lead.Delivery.NextAttempt)
+}
+
+// retryDelay удваивает задержку с каждой попыткой, но не меньше,
+// чем просит Telegram в retry_after при превышении лимитов.
+func retryDelay(attempt int, err error) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempt && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		if retryAfter := time.Duration(apiErr.RetryAfter) * time.Second; retryAfter > delay {
+			delay = retryAfter
+		}
+	}
+
+	return delay
+}
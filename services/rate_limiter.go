@@ -0,0 +1,87 @@
+package services
+
+import (
+	"pumpkin_travel_tg_bot/utils"
+	"sync"
+	"time"
+)
+
+// RateLimiter ограничивает число событий от одного пользователя в скользящем
+// окне. Отклоненные события не учитываются: поток сообщений проходит снова,
+// как только в окне освобождается место.
+type RateLimiter struct {
+	mu     sync.Mutex
+	clock  utils.Clock
+	limit  int
+	window time.Duration
+
+	events map[int64][]time.Time
+	// warned — пользователи, которых уже предупредили о превышении лимита.
+	warned    map[int64]bool
+	lastSweep time.Time
+}
+
+// NewRateLimiter создает ограничитель на limit событий за window.
+// Нулевые значения отключают ограничение.
+func NewRateLimiter(clock utils.Clock, limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		clock:  clock,
+		limit:  limit,
+		window: window,
+		events: make(map[int64][]time.Time),
+		warned: make(map[int64]bool),
+	}
+}
+
+// Allow учитывает событие пользователя и сообщает, укладывается ли он в лимит.
+// first отмечает первое отклоненное событие подряд: о превышении лимита
+// предупреждаем один раз, а не отвечаем на каждое сообщение.
+func (rl *RateLimiter) Allow(userID int64) (allowed, first bool) {
+	if rl.limit <= 0 || rl.window <= 0 {
+		return true, false
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.clock.Now()
+	rl.sweep(now)
+
+	events := recentEvents(rl.events[userID], now.Add(-rl.window))
+	if len(events) >= rl.limit {
+		rl.events[userID] = events
+		first = !rl.warned[userID]
+		rl.warned[userID] = true
+		return false, first
+	}
+
+	rl.events[userID] = append(events, now)
+	delete(rl.warned, userID)
+	return true, false
+}
+
+// sweep раз в окно забывает пользователей, от которых давно не было событий.
+func (rl *RateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < rl.window {
+		return
+	}
+	rl.lastSweep = now
+
+	since := now.Add(-rl.window)
+	for userID, events := range rl.events {
+		if len(recentEvents(events, since)) == 0 {
+			delete(rl.events, userID)
+			delete(rl.warned, userID)
+		}
+	}
+}
+
+// recentEvents отбрасывает события раньше since. События идут по возрастанию времени.
+func recentEvents(events []time.Time, since time.Time) []time.Time {
+	for i, at := range events {
+		if at.After(since) {
+			return events[i:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSlidingWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, time.August, 15, 12, 0, 0, 0, time.UTC)}
+	rl := NewRateLimiter(clock, 3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := rl.Allow(1); !allowed {
+			t.Fatalf("событие %d отклонено, хотя лимит не исчерпан", i+1)
+		}
+		clock.Advance(10 * time.Second)
+	}
+	if allowed, _ := rl.Allow(1); allowed {
+		t.Fatal("четвертое событие за минуту пропущено")
+	}
+	if allowed, _ := rl.Allow(2); !allowed {
+		t.Error("лимит одного пользователя задел другого")
+	}
+
+	// Первое событие выходит из окна — место освобождается ровно для одного.
+	clock.Advance(31 * time.Second)
+	if allowed, _ := rl.Allow(1); !allowed {
+		t.Fatal("событие отклонено после того, как первое вышло из окна")
+	}
+	if allowed, _ := rl.Allow(1); allowed {
+		t.Error("пропущено событие сверх лимита в сдвинутом окне")
+	}
+}
+
+func TestRateLimiterRejectedEventsNotCounted(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, time.August, 15, 12, 0, 0, 0, time.UTC)}
+	rl := NewRateLimiter(clock, 1, time.Minute)
+
+	rl.Allow(1)
+	// Поток отклоненных сообщений не продлевает блокировку.
+	for i := 0; i < 5; i++ {
+		clock.Advance(10 * time.Second)
+		rl.Allow(1)
+	}
+	clock.Advance(10 * time.Second)
+	if allowed, _ := rl.Allow(1); !allowed {
+		t.Error("отклоненные события продлили блокировку")
+	}
+}
+
+func TestRateLimiterWarnsOnce(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, time.August, 15, 12, 0, 0, 0, time.UTC)}
+	rl := NewRateLimiter(clock, 1, time.Minute)
+
+	rl.Allow(1)
+	if allowed, first := rl.Allow(1); allowed || !first {
+		t.Errorf("первое превышение: allowed = %v, first = %v; want false, true", allowed, first)
+	}
+	if allowed, first := rl.Allow(1); allowed || first {
+		t.Errorf("повторное превышение: allowed = %v, first = %v; want false, false", allowed, first)
+	}
+
+	// После пропущенного события о следующем превышении снова предупреждаем.
+	clock.Advance(time.Minute)
+	if allowed, _ := rl.Allow(1); !allowed {
+		t.Fatal("событие отклонено после окна")
+	}
+	if _, first := rl.Allow(1); !first {
+		t.Error("после пропущенного события не предупредили о новом превышении")
+	}
+}
+
+func TestRateLimiterSweepForgetsIdleUsers(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, time.August, 15, 12, 0, 0, 0, time.UTC)}
+	rl := NewRateLimiter(clock, 1, time.Minute)
+
+	rl.Allow(1)
+	rl.Allow(1)
+	clock.Advance(30 * time.Second)
+	rl.Allow(2)
+
+	clock.Advance(45 * time.Second)
+	rl.Allow(3)
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if _, ok := rl.events[1]; ok {
+		t.Error("события пользователя без активности дольше окна не удалены")
+	}
+	if rl.warned[1] {
+		t.Error("отметка о предупреждении пользователя без активности не удалена")
+	}
+	if _, ok := rl.events[2]; !ok {
+		t.Error("удалены события пользователя, еще не вышедшие из окна")
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, time.August, 15, 12, 0, 0, 0, time.UTC)}
+	for _, rl := range []*RateLimiter{NewRateLimiter(clock, 0, time.Minute), NewRateLimiter(clock, 1, 0)} {
+		for i := 0; i < 10; i++ {
+			if allowed, first := rl.Allow(1); !allowed || first {
+				t.Fatalf("Allow без ограничения = %v, %v; want true, false", allowed, first)
+			}
+		}
+	}
+}
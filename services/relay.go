@@ -0,0 +1,176 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// IsManagerChat сообщает, что чат — чат менеджера, куда доставляются заявки.
+func (fs *FormService) IsManagerChat(chatID int64) bool {
+	return fs.router.IsManagerChat(chatID)
+}
+
+// RelayToClient пересылает клиенту ответ менеджера на карточку заявки или на
+// пересланное сообщение клиента. ErrLeadNotFound означает, что сообщение
+// не относится к переписке по заявке.
+func (fs *FormService) RelayToClient(message *tgbotapi.Message) (*models.Lead, error) {
+	if message.ReplyToMessage == nil {
+		return nil, ErrLeadNotFound
+	}
+
+	leads, err := fs.leads.List(storage.LeadFilter{
+		ManagerChatID:    message.Chat.ID,
+		ManagerMessageID: message.ReplyToMessage.MessageID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось найти заявку: %w", err)
+	}
+	if len(leads) == 0 {
+		return nil, ErrLeadNotFound
+	}
+	lead := &leads[0]
+
+	lang := lead.Request.Language
+	name := lead.AssignedTo
+	if name == "" {
+		name = i18n.T(lang, "manager.default_name")
+	}
+	header := i18n.T(lang, "relay.header", html.EscapeString(name), lead.Number)
+	if _, err := fs.relayMessage(lead.UserInfo.ID, 0, header, message); err != nil {
+		return lead, fmt.Errorf("не удалось переслать сообщение клиенту: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"lead_number": lead.Number,
+		"manager_id":  message.From.ID,
+	}).Info("Сообщение менеджера переслано клиенту")
+
+	return lead, nil
+}
+
+// RelayToManager пересылает сообщение клиента в чат менеджера ответом на карточку
+// последней открытой заявки клиента. ErrLeadNotFound означает, что открытых
+// доставленных заявок у клиента нет. Блокировка заявок не держится, пока идет
+// отправка: она нужна только для записи номеров пересланных сообщений.
+func (fs *FormService) RelayToManager(message *tgbotapi.Message) (*models.Lead, error) {
+	fs.leadMu.Lock()
+	lead, err := fs.openLead(message.From.ID)
+	fs.leadMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	header := i18n.T(fs.router.Language(lead.Delivery.ChatID), "relay.header",
+		html.EscapeString(lead.UserInfo.DisplayName()), lead.Number)
+	messageIDs, err := fs.relayMessage(lead.Delivery.ChatID, lead.Delivery.MessageID, header, message)
+	if len(messageIDs) > 0 {
+		fs.recordRelayMessages(lead, messageIDs)
+	}
+	if err != nil {
+		return lead, fmt.Errorf("не удалось переслать сообщение менеджеру: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"lead_number": lead.Number,
+		"user_id":     message.From.ID,
+	}).Info("Сообщение клиента переслано менеджеру")
+
+	return lead, nil
+}
+
+// recordRelayMessages запоминает пересланные менеджеру сообщения, чтобы ответы
+// на них дошли до клиента. Заявка перечитывается: пока шла отправка, ее статус
+// могли изменить.
+func (fs *FormService) recordRelayMessages(lead *models.Lead, messageIDs []int) {
+	fs.leadMu.Lock()
+	defer fs.leadMu.Unlock()
+
+	current, found, err := fs.leads.Get(lead.Number)
+	if err == nil && found {
+		current.Delivery.RelayMessageIDs = append(current.Delivery.RelayMessageIDs, messageIDs...)
+		err = fs.leads.Update(*current)
+	}
+	if err != nil {
+		// Сообщение уже у менеджера, но ответить на него не получится.
+		logrus.WithError(err).WithField("lead_number", lead.Number).Error("Ошибка сохранения переписки по заявке")
+		return
+	}
+	*lead = *current
+}
+
+// openLead возвращает последнюю доставленную и не завершенную заявку клиента.
+func (fs *FormService) openLead(userID int64) (*models.Lead, error) {
+	leads, err := fs.leads.List(storage.LeadFilter{UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать заявки клиента: %w", err)
+	}
+
+	for i := len(leads) - 1; i >= 0; i-- {
+		if leads[i].Delivery.Delivered && leads[i].Delivery.MessageID != 0 && !leads[i].Status.IsFinal() {
+			return &leads[i], nil
+		}
+	}
+	return nil, ErrLeadNotFound
+}
+
+// relayMessage отправляет текст сообщения с подписью header или копию сообщения
+// с медиа. Подпись header ставится в подпись медиа, а если у сообщения подписи
+// быть не может (стикер, геопозиция, контакт), отправляется перед копией отдельно.
+// Возвращает номера отправленных сообщений.
+func (fs *FormService) relayMessage(chatID int64, replyTo int, header string, message *tgbotapi.Message) ([]int, error) {
+	if message.Text != "" {
+		msg := tgbotapi.NewMessage(chatID, header+"\n\n"+html.EscapeString(message.Text))
+		msg.ParseMode = "HTML"
+		msg.ReplyToMessageID = replyTo
+		msg.AllowSendingWithoutReply = true
+
+		sent, err := fs.bot.Send(msg)
+		if err != nil {
+			return nil, err
+		}
+		return []int{sent.MessageID}, nil
+	}
+
+	var messageIDs []int
+	copyConfig := tgbotapi.NewCopyMessage(chatID, message.Chat.ID, message.MessageID)
+	copyConfig.ReplyToMessageID = replyTo
+	copyConfig.AllowSendingWithoutReply = true
+
+	if supportsCaption(message) {
+		copyConfig.Caption = header
+		if message.Caption != "" {
+			copyConfig.Caption += "\n\n" + html.EscapeString(message.Caption)
+		}
+		copyConfig.ParseMode = "HTML"
+	} else {
+		msg := tgbotapi.NewMessage(chatID, header)
+		msg.ParseMode = "HTML"
+		msg.ReplyToMessageID = replyTo
+		msg.AllowSendingWithoutReply = true
+
+		sent, err := fs.bot.Send(msg)
+		if err != nil {
+			return nil, err
+		}
+		messageIDs = append(messageIDs, sent.MessageID)
+		copyConfig.ReplyToMessageID = sent.MessageID
+	}
+
+	copied, err := fs.bot.CopyMessage(copyConfig)
+	if err != nil {
+		return messageIDs, err
+	}
+	return append(messageIDs, copied.MessageID), nil
+}
+
+// supportsCaption сообщает, что у копии сообщения может быть подпись.
+func supportsCaption(message *tgbotapi.Message) bool {
+	return message.Photo != nil || message.Video != nil || message.Document != nil ||
+		message.Audio != nil || message.Voice != nil || message.Animation != nil
+}
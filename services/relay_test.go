@@ -0,0 +1,29 @@
+package services
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestSupportsCaption(t *testing.T) {
+	tests := []struct {
+		name    string
+		message tgbotapi.Message
+		want    bool
+	}{
+		{"photo", tgbotapi.Message{Photo: []tgbotapi.PhotoSize{{FileID: "p"}}}, true},
+		{"document", tgbotapi.Message{Document: &tgbotapi.Document{FileID: "d"}}, true},
+		{"voice", tgbotapi.Message{Voice: &tgbotapi.Voice{FileID: "v"}}, true},
+		{"video", tgbotapi.Message{Video: &tgbotapi.Video{FileID: "v"}}, true},
+		{"sticker", tgbotapi.Message{Sticker: &tgbotapi.Sticker{FileID: "s"}}, false},
+		{"location", tgbotapi.Message{Location: &tgbotapi.Location{}}, false},
+		{"contact", tgbotapi.Message{Contact: &tgbotapi.Contact{PhoneNumber: "+7"}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := supportsCaption(&tt.message); got != tt.want {
+			t.Errorf("supportsCaption(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
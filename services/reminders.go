@@ -0,0 +1,153 @@
+package services
+
+import (
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/storage"
+	"pumpkin_travel_tg_bot/utils"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// ResumeCallback — кнопка «Продолжить» в напоминании о незаконченной анкете.
+const ResumeCallback = "resume"
+
+// ReminderService напоминает клиентам о брошенных анкетах и удаляет
+// анкеты, которые не продвигались дольше срока хранения.
+type ReminderService struct {
+	bot    *tgbotapi.BotAPI
+	states storage.StateStore
+	clock  utils.Clock
+
+	// remindAfter — простой анкеты до напоминания, ttl — до удаления.
+	// Нулевое значение отключает соответствующее действие.
+	remindAfter time.Duration
+	ttl         time.Duration
+}
+
+func NewReminderService(
+	bot *tgbotapi.BotAPI,
+	states storage.StateStore,
+	clock utils.Clock,
+	remindAfter, ttl time.Duration,
+) *ReminderService {
+	return &ReminderService{
+		bot:         bot,
+		states:      states,
+		clock:       clock,
+		remindAfter: remindAfter,
+		ttl:         ttl,
+	}
+}
+
+// Run проверяет анкеты с периодом interval, пока не будет закрыт канал stop.
+func (rs *ReminderService) Run(interval time.Duration, stop <-chan struct{}) {
+	if rs.remindAfter <= 0 && rs.ttl <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			rs.CheckIdle()
+		}
+	}
+}
+
+type idleAction int
+
+const (
+	idleNone idleAction = iota
+	idleRemind
+	idleExpire
+)
+
+// CheckIdle отправляет напоминания и удаляет просроченные анкеты.
+func (rs *ReminderService) CheckIdle() {
+	states, err := rs.states.List()
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка чтения состояний диалогов")
+		return
+	}
+
+	now := rs.clock.Now()
+	for userID, state := range states {
+		if state.UpdatedAt.IsZero() || rs.idleAction(state, now) != idleNone {
+			rs.handleIdle(userID, now)
+		}
+	}
+}
+
+// handleIdle заново принимает решение по свежему состоянию под блокировкой
+// хранилища: клиент мог ответить, пока шла проверка.
+func (rs *ReminderService) handleIdle(userID int64, now time.Time) {
+	action := idleNone
+	var lang string
+	_, err := rs.states.Modify(userID, func(state *storage.UserState) bool {
+		lang = state.Request.Language
+		// Анкеты, сохраненные до появления напоминаний, отсчитываются с этой проверки.
+		if state.UpdatedAt.IsZero() {
+			state.UpdatedAt = now
+			return true
+		}
+
+		action = rs.idleAction(*state, now)
+		switch action {
+		case idleExpire:
+			return false
+		case idleRemind:
+			state.RemindedAt = now
+		}
+		return true
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Error("Ошибка сохранения состояния диалога")
+		return
+	}
+
+	switch action {
+	case idleRemind:
+		rs.sendReminder(userID, lang)
+	case idleExpire:
+		rs.sendExpired(userID, lang)
+	}
+}
+
+func (rs *ReminderService) idleAction(state storage.UserState, now time.Time) idleAction {
+	idle := now.Sub(state.UpdatedAt)
+	switch {
+	case rs.ttl > 0 && idle >= rs.ttl:
+		return idleExpire
+	case rs.remindAfter > 0 && idle >= rs.remindAfter && state.RemindedAt.IsZero():
+		return idleRemind
+	}
+	return idleNone
+}
+
+func (rs *ReminderService) sendReminder(userID int64, lang string) {
+	msg := tgbotapi.NewMessage(userID, i18n.T(lang, "reminder.text"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.continue"), ResumeCallback),
+	))
+
+	if _, err := rs.bot.Send(msg); err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Warn("Не удалось отправить напоминание об анкете")
+		return
+	}
+	logrus.WithField("user_id", userID).Info("Отправлено напоминание о незаконченной анкете")
+}
+
+func (rs *ReminderService) sendExpired(userID int64, lang string) {
+	msg := tgbotapi.NewMessage(userID, i18n.T(lang, "reminder.expired"))
+
+	if _, err := rs.bot.Send(msg); err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Warn("Не удалось сообщить об удалении анкеты")
+	}
+	logrus.WithField("user_id", userID).Info("Незаконченная анкета удалена по сроку хранения")
+}
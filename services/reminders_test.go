@@ -0,0 +1,182 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/storage"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// fakeTelegram отвечает на любой запрос к Bot API успехом и запоминает
+// тексты отправленных сообщений и названия созданных тем форума. onRequest,
+// если задан, вызывается на каждый запрос. failures задает описание ошибки,
+// которой отвечают на запросы метода.
+type fakeTelegram struct {
+	mu        sync.Mutex
+	texts     []string
+	topics    []string
+	onRequest func(method string)
+	failures  map[string]string
+}
+
+func (f *fakeTelegram) Do(req *http.Request) (*http.Response, error) {
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		req.ParseForm()
+	}
+
+	method := filepath.Base(req.URL.Path)
+	if f.onRequest != nil && method != "getMe" {
+		f.onRequest(method)
+	}
+
+	body := `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}}`
+	f.mu.Lock()
+	switch description, failed := f.failures[method]; {
+	case method == "getMe":
+		body = `{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"bot","username":"bot"}}`
+	case failed:
+		body = fmt.Sprintf(`{"ok":false,"error_code":400,"description":%q}`, description)
+	case method == "createForumTopic":
+		f.topics = append(f.topics, req.FormValue("name"))
+		body = fmt.Sprintf(`{"ok":true,"result":{"message_thread_id":%d,"name":%q}}`, 100+len(f.topics), req.FormValue("name"))
+	default:
+		f.texts = append(f.texts, req.FormValue("text"))
+	}
+	f.mu.Unlock()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+	}, nil
+}
+
+func (f *fakeTelegram) Sent() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.texts...)
+}
+
+// Topics возвращает названия созданных тем форума в порядке создания.
+func (f *fakeTelegram) Topics() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.topics...)
+}
+
+func newFakeBot(t *testing.T) (*tgbotapi.BotAPI, *fakeTelegram) {
+	t.Helper()
+
+	client := &fakeTelegram{}
+	bot, err := tgbotapi.NewBotAPIWithClient("token", tgbotapi.APIEndpoint, client)
+	if err != nil {
+		t.Fatalf("NewBotAPIWithClient() error = %v", err)
+	}
+	return bot, client
+}
+
+func newTestReminderService(t *testing.T, remindAfter, ttl time.Duration) (*ReminderService, *fakeClock, *fakeTelegram, storage.StateStore) {
+	t.Helper()
+
+	bot, client := newFakeBot(t)
+	states, err := storage.NewFileStateStore(filepath.Join(t.TempDir(), "states.json"))
+	if err != nil {
+		t.Fatalf("NewFileStateStore() error = %v", err)
+	}
+	clock := &fakeClock{now: time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)}
+
+	return NewReminderService(bot, states, clock, remindAfter, ttl), clock, client, states
+}
+
+func TestReminderServiceRemindsAndExpires(t *testing.T) {
+	const userID = 42
+	rs, clock, client, states := newTestReminderService(t, time.Hour, 24*time.Hour)
+
+	state := storage.UserState{Step: "destination", UpdatedAt: clock.Now()}
+	state.Request.Language = i18n.RU
+	if err := states.Put(userID, state); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	clock.Advance(59 * time.Minute)
+	rs.CheckIdle()
+	if sent := client.Sent(); len(sent) != 0 {
+		t.Fatalf("до ReminderAfter отправлено %q", sent)
+	}
+
+	clock.Advance(time.Minute)
+	rs.CheckIdle()
+	sent := client.Sent()
+	if len(sent) != 1 || sent[0] != i18n.T(i18n.RU, "reminder.text") {
+		t.Fatalf("после ReminderAfter отправлено %q, ожидалось одно напоминание", sent)
+	}
+	current, exists, _ := states.Get(userID)
+	if !exists || !current.RemindedAt.Equal(clock.Now()) {
+		t.Fatalf("RemindedAt не сохранен: %+v", current)
+	}
+
+	clock.Advance(time.Hour)
+	rs.CheckIdle()
+	if sent := client.Sent(); len(sent) != 1 {
+		t.Fatalf("повторное напоминание: %q", sent)
+	}
+
+	clock.Advance(22 * time.Hour)
+	rs.CheckIdle()
+	sent = client.Sent()
+	if len(sent) != 2 || sent[1] != i18n.T(i18n.RU, "reminder.expired") {
+		t.Fatalf("после StateTTL отправлено %q, ожидалось сообщение об удалении", sent)
+	}
+	if _, exists, _ := states.Get(userID); exists {
+		t.Fatal("просроченная анкета не удалена")
+	}
+}
+
+func TestReminderServiceStartsCountdownForLegacyState(t *testing.T) {
+	const userID = 7
+	rs, clock, client, states := newTestReminderService(t, time.Hour, 24*time.Hour)
+
+	if err := states.Put(userID, storage.UserState{Step: "destination"}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	rs.CheckIdle()
+	current, exists, _ := states.Get(userID)
+	if !exists || !current.UpdatedAt.Equal(clock.Now()) {
+		t.Fatalf("UpdatedAt = %v, ожидалось %v", current.UpdatedAt, clock.Now())
+	}
+	if sent := client.Sent(); len(sent) != 0 {
+		t.Fatalf("для старой анкеты сразу отправлено %q", sent)
+	}
+
+	clock.Advance(time.Hour)
+	rs.CheckIdle()
+	if sent := client.Sent(); len(sent) != 1 {
+		t.Fatalf("отправлено %q, ожидалось одно напоминание", sent)
+	}
+}
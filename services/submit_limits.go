@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/storage"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SubmitLimits ограничивает отправку заявок одним клиентом. Нулевые значения
+// отключают соответствующую проверку.
+type SubmitLimits struct {
+	// Count — сколько заявок клиент может отправить за Window.
+	Count  int
+	Window time.Duration
+	// DuplicateWindow — срок, в течение которого незавершенная заявка с теми же
+	// ответами считается уже отправленной.
+	DuplicateWindow time.Duration
+}
+
+var (
+	ErrSubmitRateLimited   = errors.New("превышен лимит отправки заявок")
+	ErrDuplicateSubmission = errors.New("заявка с такими ответами уже отправлена")
+)
+
+// createLead проверяет лимиты отправки и сохраняет заявку. Проверка и сохранение
+// выполняются под submitMu, чтобы два одновременных подтверждения не прошли
+// оба; доставка менеджеру идет уже после снятия блокировки.
+func (fs *FormService) createLead(request models.TravelRequest, userInfo models.UserInfo) (*models.Lead, error) {
+	fs.submitMu.Lock()
+	defer fs.submitMu.Unlock()
+
+	existing, err := fs.checkSubmission(&request, userInfo.ID, time.Now())
+	switch {
+	case errors.Is(err, ErrDuplicateSubmission):
+		logrus.WithFields(logrus.Fields{
+			"event":       "duplicate_submission",
+			"user_id":     userInfo.ID,
+			"username":    userInfo.Username,
+			"lead_number": existing.Number,
+		}).Warn("Повторная отправка заявки отклонена")
+		return existing, err
+	case errors.Is(err, ErrSubmitRateLimited):
+		logrus.WithFields(logrus.Fields{
+			"event":    "submit_rate_limited",
+			"user_id":  userInfo.ID,
+			"username": userInfo.Username,
+			"limit":    fs.limits.Count,
+			"window":   fs.limits.Window.String(),
+		}).Warn("Превышен лимит отправки заявок")
+		return nil, err
+	case err != nil:
+		// Лимиты защищают от флуда, но не должны мешать клиенту, если база недоступна на чтение.
+		logrus.WithError(err).WithField("user_id", userInfo.ID).Error("Ошибка проверки лимитов отправки заявки")
+	}
+
+	lead, err := fs.leads.Create(request, userInfo)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка сохранения заявки")
+		return nil, fmt.Errorf("не удалось сохранить заявку: %w", err)
+	}
+	return lead, nil
+}
+
+// checkSubmission проверяет лимиты по уже сохраненным заявкам клиента, поэтому
+// они действуют и после перезапуска бота. При ErrDuplicateSubmission
+// возвращается ранее отправленная заявка.
+func (fs *FormService) checkSubmission(request *models.TravelRequest, userID int64, now time.Time) (*models.Lead, error) {
+	if fs.limits.Count <= 0 && fs.limits.DuplicateWindow <= 0 {
+		return nil, nil
+	}
+
+	leads, err := fs.leads.List(storage.LeadFilter{UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать заявки клиента: %w", err)
+	}
+
+	recent := 0
+	for i := len(leads) - 1; i >= 0; i-- {
+		lead := &leads[i]
+		age := now.Sub(lead.CreatedAt)
+
+		if age < fs.limits.DuplicateWindow && !lead.Status.IsFinal() && lead.Request.SameAnswers(request) {
+			return lead, ErrDuplicateSubmission
+		}
+		if age < fs.limits.Window {
+			recent++
+		}
+	}
+
+	if fs.limits.Count > 0 && recent >= fs.limits.Count {
+		return nil, ErrSubmitRateLimited
+	}
+	return nil, nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/storage"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestFormService(t *testing.T, limits SubmitLimits) *FormService {
+	t.Helper()
+
+	leads, err := storage.NewFileLeadRepository(filepath.Join(t.TempDir(), "leads.json"))
+	if err != nil {
+		t.Fatalf("NewFileLeadRepository: %v", err)
+	}
+	return &FormService{leads: leads, limits: limits}
+}
+
+func TestCreateLeadRejectsDuplicates(t *testing.T) {
+	fs := newTestFormService(t, SubmitLimits{DuplicateWindow: time.Hour})
+	user := models.UserInfo{ID: 1}
+	request := models.TravelRequest{Destination: "Турция", Budget: "до 100 000 ₽"}
+
+	first, err := fs.createLead(request, user)
+	if err != nil {
+		t.Fatalf("first createLead: %v", err)
+	}
+
+	request.Destination = " турция "
+	existing, err := fs.createLead(request, user)
+	if !errors.Is(err, ErrDuplicateSubmission) || existing == nil || existing.Number != first.Number {
+		t.Fatalf("duplicate createLead = %v, %v; want lead №%d and ErrDuplicateSubmission", existing, err, first.Number)
+	}
+
+	// Другой клиент и другие ответы дубликатом не считаются.
+	if _, err := fs.createLead(request, models.UserInfo{ID: 2}); err != nil {
+		t.Errorf("createLead for another user: %v", err)
+	}
+	request.Destination = "Египет"
+	if _, err := fs.createLead(request, user); err != nil {
+		t.Errorf("createLead with other answers: %v", err)
+	}
+}
+
+func TestCreateLeadRateLimit(t *testing.T) {
+	fs := newTestFormService(t, SubmitLimits{Count: 2, Window: time.Hour})
+	user := models.UserInfo{ID: 1}
+
+	for i, destination := range []string{"Турция", "Египет"} {
+		if _, err := fs.createLead(models.TravelRequest{Destination: destination}, user); err != nil {
+			t.Fatalf("createLead #%d: %v", i+1, err)
+		}
+	}
+	if _, err := fs.createLead(models.TravelRequest{Destination: "ОАЭ"}, user); !errors.Is(err, ErrSubmitRateLimited) {
+		t.Errorf("third createLead error = %v, want ErrSubmitRateLimited", err)
+	}
+}
+
+func TestCreateLeadConcurrentDuplicates(t *testing.T) {
+	fs := newTestFormService(t, SubmitLimits{DuplicateWindow: time.Hour})
+	user := models.UserInfo{ID: 1}
+	request := models.TravelRequest{Destination: "Турция"}
+
+	const attempts = 5
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 0
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := fs.createLead(request, user); err == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created != 1 {
+		t.Errorf("created %d leads from concurrent identical submissions, want 1", created)
+	}
+}
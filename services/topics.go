@@ -0,0 +1,177 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pumpkin_travel_tg_bot/catalog"
+	"pumpkin_travel_tg_bot/config"
+	"pumpkin_travel_tg_bot/i18n"
+	"pumpkin_travel_tg_bot/models"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// maxTopicNameLength — ограничение Telegram на длину названия темы.
+const maxTopicNameLength = 128
+
+// leadTopic возвращает тему форума для карточки заявки в чате chatID или 0,
+// если темы не используются или создать тему не удалось: тогда карточка
+// отправляется в общий поток чата.
+func (fs *FormService) leadTopic(chatID int64, lead *models.Lead) int {
+	// Тема, созданная при прошлой попытке доставки, используется повторно.
+	if lead.Delivery.TopicID != 0 {
+		return lead.Delivery.TopicID
+	}
+
+	lang := fs.router.Language(chatID)
+	switch config.AppConfig.ManagerTopics {
+	case config.TopicsPerRequest:
+		threadID, err := fs.createTopic(chatID, requestTopicName(lead, lang))
+		if err != nil {
+			logrus.WithError(err).WithField("lead_number", lead.Number).Warn("Не удалось создать тему для заявки")
+			return 0
+		}
+		lead.Delivery.TopicID = threadID
+		lead.Delivery.OwnTopic = true
+
+	case config.TopicsPerDestination:
+		threadID, err := fs.sharedTopic(chatID, destinationTopicName(&lead.Request, lang))
+		if err != nil {
+			logrus.WithError(err).WithField("lead_number", lead.Number).Warn("Не удалось получить тему направления")
+			return 0
+		}
+		lead.Delivery.TopicID = threadID
+	}
+
+	return lead.Delivery.TopicID
+}
+
+// sharedTopic возвращает тему с названием name, создавая ее при первом обращении.
+func (fs *FormService) sharedTopic(chatID int64, name string) (int, error) {
+	fs.topicMu.Lock()
+	defer fs.topicMu.Unlock()
+
+	threadID, exists, err := fs.topics.Get(chatID, name)
+	if err != nil {
+		return 0, err
+	}
+	if exists {
+		return threadID, nil
+	}
+
+	threadID, err = fs.createTopic(chatID, name)
+	if err != nil {
+		return 0, err
+	}
+	if err := fs.topics.Put(chatID, name, threadID); err != nil {
+		logrus.WithError(err).WithField("topic", name).Error("Ошибка сохранения темы форума")
+	}
+	return threadID, nil
+}
+
+// forgetTopic сбрасывает в delivery тему заявки, если ее удалили в чате:
+// при следующей попытке доставки тема будет создана заново.
+func (fs *FormService) forgetTopic(chatID int64, request *models.TravelRequest, delivery *models.LeadDelivery) {
+	if !delivery.OwnTopic {
+		if err := fs.topics.Delete(chatID, destinationTopicName(request, fs.router.Language(chatID))); err != nil {
+			logrus.WithError(err).Error("Ошибка удаления темы форума")
+		}
+	}
+	delivery.TopicID = 0
+	delivery.OwnTopic = false
+}
+
+func (fs *FormService) createTopic(chatID int64, name string) (int, error) {
+	params := tgbotapi.Params{"name": name}
+	params.AddNonZero64("chat_id", chatID)
+
+	resp, err := fs.bot.MakeRequest("createForumTopic", params)
+	if err != nil {
+		return 0, err
+	}
+
+	var topic struct {
+		MessageThreadID int `json:"message_thread_id"`
+	}
+	if err := json.Unmarshal(resp.Result, &topic); err != nil {
+		return 0, fmt.Errorf("некорректный ответ createForumTopic: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"chat_id":   chatID,
+		"thread_id": topic.MessageThreadID,
+		"topic":     name,
+	}).Info("Создана тема форума")
+
+	return topic.MessageThreadID, nil
+}
+
+// sendToTopic отправляет HTML-сообщение в тему форума. Библиотека не умеет
+// передавать message_thread_id, поэтому запрос собирается вручную.
+func (fs *FormService) sendToTopic(chatID int64, threadID int, text string, keyboard *tgbotapi.InlineKeyboardMarkup) (tgbotapi.Message, error) {
+	params := tgbotapi.Params{"text": text, "parse_mode": tgbotapi.ModeHTML}
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonZero("message_thread_id", threadID)
+	if err := params.AddInterface("reply_markup", keyboard); err != nil {
+		return tgbotapi.Message{}, err
+	}
+
+	resp, err := fs.bot.MakeRequest("sendMessage", params)
+	if err != nil {
+		return tgbotapi.Message{}, err
+	}
+
+	var message tgbotapi.Message
+	if err := json.Unmarshal(resp.Result, &message); err != nil {
+		return tgbotapi.Message{}, fmt.Errorf("некорректный ответ sendMessage: %w", err)
+	}
+	return message, nil
+}
+
+// isTopicMissing распознает ошибку отправки в удаленную или закрытую тему.
+func isTopicMissing(err error) bool {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	description := strings.ToLower(apiErr.Message)
+	return strings.Contains(description, "thread not found") || strings.Contains(description, "topic_closed") ||
+		strings.Contains(description, "topic_deleted")
+}
+
+func requestTopicName(lead *models.Lead, lang string) string {
+	destinations := strings.Join(lead.Request.Destinations, ", ")
+	if destinations == "" {
+		destinations = i18n.T(lang, "topic.undecided")
+	}
+	return truncateRunes(fmt.Sprintf("№%d · %s · %s", lead.Number, destinations, lead.UserInfo.DisplayName()), maxTopicNameLength)
+}
+
+// destinationTopicName — название общей темы для заявки: страна первого
+// направления, курорты объединяются в тему своей страны.
+func destinationTopicName(request *models.TravelRequest, lang string) string {
+	if len(request.Destinations) == 0 {
+		return i18n.T(lang, "topic.undecided")
+	}
+
+	// Курорт хранится вместе со страной: «Анталья (Турция)».
+	name, _, _ := strings.Cut(request.Destinations[0], " (")
+	if destination, exact, ok := catalog.FindDestination(name); ok && exact {
+		name = destination.Name
+		if destination.Country != "" {
+			name = destination.Country
+		}
+	}
+	return truncateRunes(name, maxTopicNameLength)
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"path/filepath"
+	"pumpkin_travel_tg_bot/config"
+	"pumpkin_travel_tg_bot/models"
+	"pumpkin_travel_tg_bot/storage"
+	"sync"
+	"testing"
+)
+
+const topicsChatID = -100
+
+// newTestTopicService создает сервис с хранилищем тем и включает темы форума
+// в режиме mode до конца теста.
+func newTestTopicService(t *testing.T, mode string) (*FormService, *fakeTelegram) {
+	t.Helper()
+
+	saved := config.AppConfig
+	config.AppConfig.ManagerTopics = mode
+	t.Cleanup(func() { config.AppConfig = saved })
+
+	fs, client := newTestFormServiceWithBot(t, topicsChatID)
+	topics, err := storage.NewFileTopicStore(filepath.Join(t.TempDir(), "topics.json"))
+	if err != nil {
+		t.Fatalf("NewFileTopicStore: %v", err)
+	}
+	fs.topics = topics
+	return fs, client
+}
+
+// topicLead сохраняет заявку клиента 1 в направление destinations для чата менеджеров.
+func topicLead(t *testing.T, fs *FormService, destinations ...string) *models.Lead {
+	t.Helper()
+
+	request := models.TravelRequest{Destination: "море", Destinations: destinations, Language: "ru"}
+	lead, err := fs.leads.Create(request, models.UserInfo{ID: 1, FirstName: "Анна"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	lead.AssignedChatID = topicsChatID
+	if err := fs.leads.Update(*lead); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	return lead
+}
+
+func TestLeadTopicPerRequest(t *testing.T) {
+	fs, client := newTestTopicService(t, config.TopicsPerRequest)
+	lead := topicLead(t, fs, "Турция")
+
+	threadID := fs.leadTopic(topicsChatID, lead)
+	if threadID == 0 || lead.Delivery.TopicID != threadID || !lead.Delivery.OwnTopic {
+		t.Fatalf("leadTopic = %d, Delivery = %+v; want own topic", threadID, lead.Delivery)
+	}
+	if topics := client.Topics(); len(topics) != 1 || topics[0] != "№1 · Турция · Анна" {
+		t.Errorf("созданы темы %q, want [№1 · Турция · Анна]", topics)
+	}
+
+	// Повторная попытка доставки идет в уже созданную тему.
+	if again := fs.leadTopic(topicsChatID, lead); again != threadID {
+		t.Errorf("повторный leadTopic = %d, want %d", again, threadID)
+	}
+	if topics := client.Topics(); len(topics) != 1 {
+		t.Errorf("при повторной доставке созданы темы %q", topics)
+	}
+}
+
+func TestLeadTopicPerDestinationSharesTopic(t *testing.T) {
+	fs, client := newTestTopicService(t, config.TopicsPerDestination)
+	first := topicLead(t, fs, "Турция")
+	second := topicLead(t, fs, "Анталья (Турция)", "Египет")
+
+	threadID := fs.leadTopic(topicsChatID, first)
+	if threadID == 0 || first.Delivery.OwnTopic {
+		t.Fatalf("leadTopic = %d, Delivery = %+v; want shared topic", threadID, first.Delivery)
+	}
+	if got := fs.leadTopic(topicsChatID, second); got != threadID {
+		t.Errorf("курорт страны попал в тему %d, want %d", got, threadID)
+	}
+	if topics := client.Topics(); len(topics) != 1 || topics[0] != "Турция" {
+		t.Errorf("созданы темы %q, want [Турция]", topics)
+	}
+
+	stored, exists, err := fs.topics.Get(topicsChatID, "Турция")
+	if err != nil || !exists || stored != threadID {
+		t.Errorf("topics.Get(Турция) = %d, %v, %v; want %d", stored, exists, err, threadID)
+	}
+}
+
+func TestSharedTopicCreatedOnceConcurrently(t *testing.T) {
+	fs, client := newTestTopicService(t, config.TopicsPerDestination)
+
+	var wg sync.WaitGroup
+	threads := make([]int, 5)
+	for i := range threads {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			threads[i], _ = fs.sharedTopic(topicsChatID, "Египет")
+		}(i)
+	}
+	wg.Wait()
+
+	if topics := client.Topics(); len(topics) != 1 {
+		t.Fatalf("созданы темы %q, want одну", topics)
+	}
+	for _, threadID := range threads {
+		if threadID != threads[0] || threadID == 0 {
+			t.Fatalf("темы у одновременных заявок = %v, want одну и ту же", threads)
+		}
+	}
+}
+
+func TestLeadTopicDisabled(t *testing.T) {
+	fs, client := newTestTopicService(t, "")
+	lead := topicLead(t, fs, "Турция")
+
+	if threadID := fs.leadTopic(topicsChatID, lead); threadID != 0 {
+		t.Errorf("leadTopic без тем = %d, want 0", threadID)
+	}
+	if topics := client.Topics(); len(topics) != 0 {
+		t.Errorf("без тем созданы темы %q", topics)
+	}
+}
+
+func TestLeadTopicFallsBackToGeneralChat(t *testing.T) {
+	fs, client := newTestTopicService(t, config.TopicsPerRequest)
+	client.failures = map[string]string{"createForumTopic": "Bad Request: the chat is not a forum"}
+	lead := topicLead(t, fs, "Турция")
+
+	if threadID := fs.leadTopic(topicsChatID, lead); threadID != 0 || lead.Delivery.TopicID != 0 {
+		t.Errorf("leadTopic при ошибке создания = %d, Delivery = %+v; want 0", threadID, lead.Delivery)
+	}
+}
+
+func TestDeliverLeadForgetsDeletedTopic(t *testing.T) {
+	fs, client := newTestTopicService(t, config.TopicsPerDestination)
+	if err := fs.topics.Put(topicsChatID, "Турция", 7); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	client.failures = map[string]string{"sendMessage": "Bad Request: message thread not found"}
+	lead := topicLead(t, fs, "Турция")
+
+	fs.deliverLead(lead)
+
+	if lead.Delivery.Delivered || lead.Delivery.TopicID != 0 {
+		t.Errorf("Delivery = %+v; want undelivered without topic", lead.Delivery)
+	}
+	if _, exists, _ := fs.topics.Get(topicsChatID, "Турция"); exists {
+		t.Error("удаленная тема осталась в хранилище")
+	}
+
+	// Следующая попытка создает тему заново.
+	client.failures = nil
+	fs.deliverLead(lead)
+	if !lead.Delivery.Delivered || lead.Delivery.TopicID == 0 || lead.Delivery.TopicID == 7 {
+		t.Errorf("Delivery после повтора = %+v; want delivered to a new topic", lead.Delivery)
+	}
+}
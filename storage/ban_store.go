@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Ban — блокировка пользователя администратором.
+type Ban struct {
+	Reason   string    `json:"reason,omitempty"`
+	BannedBy int64     `json:"banned_by"`
+	BannedAt time.Time `json:"banned_at"`
+}
+
+// BanStore хранит список заблокированных пользователей: их сообщения
+// и нажатия кнопок бот игнорирует.
+type BanStore interface {
+	Get(userID int64) (Ban, bool, error)
+	Put(userID int64, ban Ban) error
+	// Delete снимает блокировку. Возвращает false, если пользователь не был заблокирован.
+	Delete(userID int64) (bool, error)
+	List() (map[int64]Ban, error)
+}
+
+// FileBanStore держит блокировки в памяти и сохраняет их в JSON-файл
+// после каждого изменения.
+type FileBanStore struct {
+	mu   sync.Mutex
+	path string
+	bans map[int64]Ban
+}
+
+func NewFileBanStore(path string) (*FileBanStore, error) {
+	store := &FileBanStore{
+		path: path,
+		bans: make(map[int64]Ban),
+	}
+
+	if err := readJSONFile(path, &store.bans); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать блокировки из %s: %w", path, err)
+	}
+	if store.bans == nil {
+		store.bans = make(map[int64]Ban)
+	}
+
+	return store, nil
+}
+
+func (s *FileBanStore) Get(userID int64) (Ban, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ban, exists := s.bans[userID]
+	return ban, exists, nil
+}
+
+func (s *FileBanStore) Put(userID int64, ban Ban) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bans[userID] = ban
+	return writeJSONFile(s.path, s.bans)
+}
+
+func (s *FileBanStore) Delete(userID int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.bans[userID]; !exists {
+		return false, nil
+	}
+	delete(s.bans, userID)
+	return true, writeJSONFile(s.path, s.bans)
+}
+
+func (s *FileBanStore) List() (map[int64]Ban, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[int64]Ban, len(s.bans))
+	for userID, ban := range s.bans {
+		result[userID] = ban
+	}
+	return result, nil
+}
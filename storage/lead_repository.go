@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"fmt"
+	"pumpkin_travel_tg_bot/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LeadFilter ограничивает выборку заявок. Нулевые поля не учитываются.
+type LeadFilter struct {
+	UserID      int64
+	Status      models.LeadStatus
+	Undelivered bool
+	// Open отбирает заявки, работа с которыми не завершена.
+	Open bool
+	// AssignedChatID и AssignedTo отбирают заявки, направленные в чат менеджера
+	// и конкретному менеджеру в нем.
+	AssignedChatID int64
+	AssignedTo     string
+
+	// BudgetMin и BudgetMax отбирают заявки, бюджет которых пересекается
+	// с диапазоном в валюте Currency. Заявки без разобранного бюджета не попадают в выборку.
+	BudgetMin int64
+	BudgetMax int64
+	Currency  string
+
+	// ManagerChatID и ManagerMessageID отбирают заявку по ее сообщению в чате менеджера.
+	ManagerChatID    int64
+	ManagerMessageID int
+
+	// SortByBudget сортирует заявки по убыванию бюджета вместо порядка создания.
+	SortByBudget bool
+}
+
+func (f LeadFilter) matches(lead models.Lead) bool {
+	if f.UserID != 0 && lead.UserInfo.ID != f.UserID {
+		return false
+	}
+	if f.Status != "" && lead.Status != f.Status {
+		return false
+	}
+	if f.Undelivered && lead.Delivery.Delivered {
+		return false
+	}
+	if f.Open && lead.Status.IsFinal() {
+		return false
+	}
+	if f.AssignedChatID != 0 && lead.AssignedChatID != f.AssignedChatID {
+		return false
+	}
+	if f.AssignedTo != "" && lead.AssignedTo != f.AssignedTo {
+		return false
+	}
+	if f.ManagerMessageID != 0 && !lead.HasManagerMessage(f.ManagerChatID, f.ManagerMessageID) {
+		return false
+	}
+	if f.BudgetMin != 0 || f.BudgetMax != 0 || f.Currency != "" {
+		budget := lead.Request.BudgetRange
+		if budget == nil {
+			return false
+		}
+		if f.Currency != "" && budget.Currency != f.Currency {
+			return false
+		}
+		if !budget.Overlaps(f.BudgetMin, f.BudgetMax) {
+			return false
+		}
+	}
+	return true
+}
+
+// LeadRepository хранит историю всех подтвержденных заявок.
+type LeadRepository interface {
+	Create(request models.TravelRequest, userInfo models.UserInfo) (*models.Lead, error)
+	Get(number int64) (*models.Lead, bool, error)
+	Update(lead models.Lead) error
+	// UpdateDelivery записывает только состояние доставки, не затрагивая статус
+	// и остальные поля, и возвращает заявку после изменения.
+	UpdateDelivery(number int64, delivery models.LeadDelivery) (*models.Lead, error)
+	List(filter LeadFilter) ([]models.Lead, error)
+}
+
+type leadFile struct {
+	LastNumber int64                 `json:"last_number"`
+	Leads      map[int64]models.Lead `json:"leads"`
+}
+
+// FileLeadRepository хранит заявки в JSON-файле и присваивает им
+// последовательные номера.
+type FileLeadRepository struct {
+	mu   sync.Mutex
+	path string
+	data leadFile
+}
+
+func NewFileLeadRepository(path string) (*FileLeadRepository, error) {
+	repo := &FileLeadRepository{
+		path: path,
+		data: leadFile{Leads: make(map[int64]models.Lead)},
+	}
+
+	if err := readJSONFile(path, &repo.data); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать заявки из %s: %w", path, err)
+	}
+	if repo.data.Leads == nil {
+		repo.data.Leads = make(map[int64]models.Lead)
+	}
+
+	return repo, nil
+}
+
+func (r *FileLeadRepository) Create(request models.TravelRequest, userInfo models.UserInfo) (*models.Lead, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	lead := models.Lead{
+		Number:    r.data.LastNumber + 1,
+		Status:    models.LeadStatusNew,
+		Request:   request,
+		UserInfo:  userInfo,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	r.data.Leads[lead.Number] = lead
+	r.data.LastNumber = lead.Number
+
+	if err := writeJSONFile(r.path, r.data); err != nil {
+		delete(r.data.Leads, lead.Number)
+		r.data.LastNumber--
+		return nil, err
+	}
+
+	return &lead, nil
+}
+
+func (r *FileLeadRepository) Get(number int64) (*models.Lead, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lead, exists := r.data.Leads[number]
+	if !exists {
+		return nil, false, nil
+	}
+
+	return &lead, true, nil
+}
+
+func (r *FileLeadRepository) Update(lead models.Lead) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, exists := r.data.Leads[lead.Number]
+	if !exists {
+		return fmt.Errorf("заявка №%d не найдена", lead.Number)
+	}
+
+	lead.UpdatedAt = time.Now()
+	r.data.Leads[lead.Number] = lead
+
+	if err := writeJSONFile(r.path, r.data); err != nil {
+		r.data.Leads[lead.Number] = previous
+		return err
+	}
+
+	return nil
+}
+
+func (r *FileLeadRepository) UpdateDelivery(number int64, delivery models.LeadDelivery) (*models.Lead, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, exists := r.data.Leads[number]
+	if !exists {
+		return nil, fmt.Errorf("заявка №%d не найдена", number)
+	}
+
+	lead := previous
+	lead.Delivery = delivery
+	lead.UpdatedAt = time.Now()
+	r.data.Leads[number] = lead
+
+	if err := writeJSONFile(r.path, r.data); err != nil {
+		r.data.Leads[number] = previous
+		return nil, err
+	}
+
+	return &lead, nil
+}
+
+// List возвращает подходящие под фильтр заявки в порядке их создания
+// или по убыванию бюджета, если задан SortByBudget.
+func (r *FileLeadRepository) List(filter LeadFilter) ([]models.Lead, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var leads []models.Lead
+	for _, lead := range r.data.Leads {
+		if filter.matches(lead) {
+			leads = append(leads, lead)
+		}
+	}
+
+	sort.Slice(leads, func(i, j int) bool {
+		if filter.SortByBudget {
+			bi, bj := budgetUpper(leads[i]), budgetUpper(leads[j])
+			if bi != bj {
+				return bi > bj
+			}
+		}
+		return leads[i].Number < leads[j].Number
+	})
+
+	return leads, nil
+}
+
+func budgetUpper(lead models.Lead) int64 {
+	if lead.Request.BudgetRange == nil {
+		return 0
+	}
+	return lead.Request.BudgetRange.Upper()
+}
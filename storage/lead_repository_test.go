@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"path/filepath"
+	"pumpkin_travel_tg_bot/models"
+	"testing"
+)
+
+func TestFileLeadRepositoryUpdateDeliveryKeepsStatus(t *testing.T) {
+	repo, err := NewFileLeadRepository(filepath.Join(t.TempDir(), "leads.json"))
+	if err != nil {
+		t.Fatalf("NewFileLeadRepository: %v", err)
+	}
+
+	lead, err := repo.Create(models.TravelRequest{Destination: "Турция"}, models.UserInfo{ID: 1})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	stale := *lead
+
+	// Клиент отменил заявку, пока очередь повторов отправляла ее менеджеру.
+	lead.Status = models.LeadStatusCancelled
+	if err := repo.Update(*lead); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	delivery := stale.Delivery
+	delivery.Attempts++
+	delivery.Delivered = true
+	delivery.MessageID = 42
+	updated, err := repo.UpdateDelivery(stale.Number, delivery)
+	if err != nil {
+		t.Fatalf("UpdateDelivery: %v", err)
+	}
+
+	stored, _, err := repo.Get(lead.Number)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	for _, got := range []*models.Lead{updated, stored} {
+		if got.Status != models.LeadStatusCancelled {
+			t.Errorf("Status = %s, want %s", got.Status, models.LeadStatusCancelled)
+		}
+		if !got.Delivery.Delivered || got.Delivery.MessageID != 42 || got.Delivery.Attempts != 1 {
+			t.Errorf("Delivery = %+v, want delivered message 42 after 1 attempt", got.Delivery)
+		}
+	}
+
+	if _, err := repo.UpdateDelivery(100, delivery); err == nil {
+		t.Error("UpdateDelivery for a missing lead: want error")
+	}
+}
+
+func TestLeadFilterAssignedTo(t *testing.T) {
+	repo, err := NewFileLeadRepository(filepath.Join(t.TempDir(), "leads.json"))
+	if err != nil {
+		t.Fatalf("NewFileLeadRepository: %v", err)
+	}
+
+	for _, name := range []string{"Анна", "Борис", "Анна"} {
+		lead, err := repo.Create(models.TravelRequest{}, models.UserInfo{ID: 1})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		lead.AssignedChatID = 10
+		lead.AssignedTo = name
+		if err := repo.Update(*lead); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+
+	tests := []struct {
+		filter LeadFilter
+		want   int
+	}{
+		{LeadFilter{AssignedChatID: 10, Open: true}, 3},
+		{LeadFilter{AssignedChatID: 10, AssignedTo: "Анна", Open: true}, 2},
+		{LeadFilter{AssignedChatID: 10, AssignedTo: "Борис", Open: true}, 1},
+		{LeadFilter{AssignedChatID: 20, AssignedTo: "Анна", Open: true}, 0},
+	}
+	for _, tt := range tests {
+		leads, err := repo.List(tt.filter)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(leads) != tt.want {
+			t.Errorf("List(%+v) = %d заявок, want %d", tt.filter, len(leads), tt.want)
+		}
+	}
+}
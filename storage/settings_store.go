@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+)
+
+// UserSettings — настройки, которые пользователь выбрал сам. Пустое
+// значение означает, что настройка не выбиралась.
+type UserSettings struct {
+	// Language — язык бота, выбранный командой /language.
+	Language string `json:"language,omitempty"`
+}
+
+// SettingsStore хранит настройки пользователей. В отличие от состояния
+// диалога, настройки не удаляются после отправки заявки.
+type SettingsStore interface {
+	Get(userID int64) (UserSettings, error)
+	Put(userID int64, settings UserSettings) error
+}
+
+// FileSettingsStore держит настройки в памяти и сохраняет их в JSON-файл
+// после каждого изменения.
+type FileSettingsStore struct {
+	mu       sync.Mutex
+	path     string
+	settings map[int64]UserSettings
+}
+
+func NewFileSettingsStore(path string) (*FileSettingsStore, error) {
+	store := &FileSettingsStore{
+		path:     path,
+		settings: make(map[int64]UserSettings),
+	}
+
+	if err := readJSONFile(path, &store.settings); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать настройки из %s: %w", path, err)
+	}
+	if store.settings == nil {
+		store.settings = make(map[int64]UserSettings)
+	}
+
+	return store, nil
+}
+
+func (s *FileSettingsStore) Get(userID int64) (UserSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.settings[userID], nil
+}
+
+func (s *FileSettingsStore) Put(userID int64, settings UserSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.settings[userID]
+	s.settings[userID] = settings
+
+	if err := writeJSONFile(s.path, s.settings); err != nil {
+		if existed {
+			s.settings[userID] = previous
+		} else {
+			delete(s.settings, userID)
+		}
+		return err
+	}
+	return nil
+}
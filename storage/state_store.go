@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"fmt"
+	"pumpkin_travel_tg_bot/models"
+	"sync"
+	"time"
+)
+
+// UserState — незавершенная анкета пользователя и текущий шаг диалога.
+// Шаг — ключ текущего вопроса анкеты.
+type UserState struct {
+	Request models.TravelRequest `json:"request"`
+	Step    string               `json:"step_key"`
+	// History — ключи отвеченных вопросов по порядку, для возврата назад.
+	History []string `json:"history,omitempty"`
+	// Editing — клиент правит отдельный ответ и после него вернется к подтверждению.
+	Editing bool `json:"editing,omitempty"`
+
+	// Selected — отмеченные варианты вопроса с множественным выбором.
+	Selected []string `json:"selected,omitempty"`
+	// Adults и Children — значения счетчиков туристов на кнопках.
+	Adults   int `json:"adults,omitempty"`
+	Children int `json:"children,omitempty"`
+
+	// Previous — прошлая заявка клиента при /repeat: ее ответы можно оставить кнопкой.
+	Previous *models.TravelRequest `json:"previous,omitempty"`
+
+	// Suggestion — предложенное исправление ответа, которое ждет решения клиента.
+	Suggestion *AnswerSuggestion `json:"suggestion,omitempty"`
+
+	// UpdatedAt — время последнего продвижения по анкете; RemindedAt — когда
+	// клиенту напомнили о незаконченной анкете после этого.
+	UpdatedAt  time.Time `json:"updated_at,omitempty"`
+	RemindedAt time.Time `json:"reminded_at,omitempty"`
+}
+
+// AnswerSuggestion — исходный ответ клиента и варианты его исправления.
+type AnswerSuggestion struct {
+	Answer  string   `json:"answer"`
+	Options []string `json:"options"`
+}
+
+// Clone возвращает копию состояния, не разделяющую с оригиналом срезы и
+// указатели. Хранилище отдает и сохраняет только копии, поэтому обработчики
+// могут менять полученное состояние без блокировки хранилища.
+func (s UserState) Clone() UserState {
+	clone := s
+	clone.Request = s.Request.Clone()
+	clone.History = append([]string(nil), s.History...)
+	clone.Selected = append([]string(nil), s.Selected...)
+	if s.Previous != nil {
+		previous := s.Previous.Clone()
+		clone.Previous = &previous
+	}
+	if s.Suggestion != nil {
+		suggestion := *s.Suggestion
+		suggestion.Options = append([]string(nil), s.Suggestion.Options...)
+		clone.Suggestion = &suggestion
+	}
+	return clone
+}
+
+// StateStore хранит состояние диалогов между перезапусками бота.
+type StateStore interface {
+	Get(userID int64) (*UserState, bool, error)
+	Put(userID int64, state UserState) error
+	Delete(userID int64) error
+	// List возвращает копии состояний всех незавершенных диалогов.
+	List() (map[int64]UserState, error)
+	// Modify атомарно применяет change к сохраненному состоянию; если change
+	// возвращает false, состояние удаляется. Возвращает false, если состояния нет.
+	Modify(userID int64, change func(state *UserState) bool) (bool, error)
+}
+
+// FileStateStore держит состояния в памяти и сохраняет их в JSON-файл
+// после каждого изменения.
+type FileStateStore struct {
+	mu     sync.Mutex
+	path   string
+	states map[int64]UserState
+}
+
+func NewFileStateStore(path string) (*FileStateStore, error) {
+	store := &FileStateStore{
+		path:   path,
+		states: make(map[int64]UserState),
+	}
+
+	if err := readJSONFile(path, &store.states); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать состояния из %s: %w", path, err)
+	}
+
+	return store, nil
+}
+
+func (s *FileStateStore) Get(userID int64) (*UserState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, exists := s.states[userID]
+	if !exists {
+		return nil, false, nil
+	}
+
+	state = state.Clone()
+	return &state, true, nil
+}
+
+func (s *FileStateStore) Put(userID int64, state UserState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.states[userID]
+	s.states[userID] = state.Clone()
+	return s.save(userID, previous, existed)
+}
+
+func (s *FileStateStore) Delete(userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.states[userID]; !exists {
+		return nil
+	}
+
+	previous := s.states[userID]
+	delete(s.states, userID)
+	return s.save(userID, previous, true)
+}
+
+func (s *FileStateStore) List() (map[int64]UserState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make(map[int64]UserState, len(s.states))
+	for userID, state := range s.states {
+		states[userID] = state.Clone()
+	}
+
+	return states, nil
+}
+
+func (s *FileStateStore) Modify(userID int64, change func(state *UserState) bool) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, exists := s.states[userID]
+	if !exists {
+		return false, nil
+	}
+
+	previous := state
+	state = state.Clone()
+	if change(&state) {
+		s.states[userID] = state
+	} else {
+		delete(s.states, userID)
+	}
+	return true, s.save(userID, previous, true)
+}
+
+// save записывает состояния в файл. Если запись не удалась, состояние
+// пользователя в памяти возвращается к previous, чтобы память не расходилась с диском.
+func (s *FileStateStore) save(userID int64, previous UserState, existed bool) error {
+	err := writeJSONFile(s.path, s.states)
+	if err == nil {
+		return nil
+	}
+
+	if existed {
+		s.states[userID] = previous
+	} else {
+		delete(s.states, userID)
+	}
+	return err
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"pumpkin_travel_tg_bot/models"
+	"sync"
+	"testing"
+)
+
+func newTestStateStore(t *testing.T) *FileStateStore {
+	t.Helper()
+
+	store, err := NewFileStateStore(filepath.Join(t.TempDir(), "states.json"))
+	if err != nil {
+		t.Fatalf("NewFileStateStore: %v", err)
+	}
+	return store
+}
+
+func TestFileStateStoreGetReturnsIndependentCopy(t *testing.T) {
+	store := newTestStateStore(t)
+	state := UserState{
+		Step:     "child_age",
+		History:  []string{"destination"},
+		Selected: []string{"Пляжный"},
+		Request: models.TravelRequest{
+			Party:        &models.TravelParty{Adults: 2, Children: 1, ChildAges: []int{5}},
+			ExtraAnswers: []models.ExtraAnswer{{Key: "pets", Value: "нет"}},
+		},
+	}
+	if err := store.Put(1, state); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	// Изменения переданного в Put состояния не должны попадать в хранилище.
+	state.Request.Party.ChildAges[0] = 9
+	state.History[0] = "changed"
+
+	got, _, err := store.Get(1)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got.Request.Party.ChildAges = append(got.Request.Party.ChildAges[:0], 7, 8)
+	got.Request.Party.Children = 2
+	got.Selected[0] = "changed"
+	got.Request.ExtraAnswers[0].Value = "changed"
+
+	stored, _, err := store.Get(1)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if ages := stored.Request.Party.ChildAges; len(ages) != 1 || ages[0] != 5 {
+		t.Errorf("stored ChildAges = %v, want [5]", ages)
+	}
+	if stored.Request.Party.Children != 1 {
+		t.Errorf("stored Children = %d, want 1", stored.Request.Party.Children)
+	}
+	if stored.History[0] != "destination" || stored.Selected[0] != "Пляжный" {
+		t.Errorf("stored History = %v, Selected = %v", stored.History, stored.Selected)
+	}
+	if stored.Request.ExtraAnswers[0].Value != "нет" {
+		t.Errorf("stored extra answer = %q, want %q", stored.Request.ExtraAnswers[0].Value, "нет")
+	}
+}
+
+// TestFileStateStoreConcurrentUpdates рассчитан на запуск с -race: несколько
+// пользователей обновляют свои анкеты одновременно, а у одного пользователя
+// обновления идут из нескольких горутин подряд.
+func TestFileStateStoreConcurrentUpdates(t *testing.T) {
+	store := newTestStateStore(t)
+	const users, updates = 4, 20
+
+	for userID := int64(1); userID <= users; userID++ {
+		state := UserState{Request: models.TravelRequest{Party: &models.TravelParty{Adults: 2, Children: 1}}}
+		if err := store.Put(userID, state); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for userID := int64(1); userID <= users; userID++ {
+		// Две горутины на пользователя — повторные обновления одной анкеты.
+		for worker := 0; worker < 2; worker++ {
+			wg.Add(1)
+			go func(userID int64) {
+				defer wg.Done()
+				for i := 0; i < updates; i++ {
+					state, exists, err := store.Get(userID)
+					if err != nil || !exists {
+						t.Errorf("Get(%d) = %v, %v", userID, exists, err)
+						return
+					}
+					state.Request.Party.ChildAges = append(state.Request.Party.ChildAges, i)
+					state.History = append(state.History, "step")
+					if err := store.Put(userID, *state); err != nil {
+						t.Errorf("Put(%d): %v", userID, err)
+						return
+					}
+				}
+			}(userID)
+		}
+
+		wg.Add(1)
+		go func(userID int64) {
+			defer wg.Done()
+			for i := 0; i < updates; i++ {
+				_, err := store.Modify(userID, func(state *UserState) bool {
+					state.Request.Party.ChildAges = nil
+					return true
+				})
+				if err != nil {
+					t.Errorf("Modify(%d): %v", userID, err)
+					return
+				}
+				if _, err := store.List(); err != nil {
+					t.Errorf("List: %v", err)
+					return
+				}
+			}
+		}(userID)
+	}
+	wg.Wait()
+
+	states, err := store.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(states) != users {
+		t.Errorf("len(states) = %d, want %d", len(states), users)
+	}
+}
+
+// breakPath направляет запись в каталог, который нельзя создать: на его месте файл.
+func breakPath(t *testing.T) string {
+	t.Helper()
+
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return filepath.Join(blocker, "data.json")
+}
+
+func TestFileStateStoreRollsBackOnWriteError(t *testing.T) {
+	store := newTestStateStore(t)
+	if err := store.Put(1, UserState{Step: "destination"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	store.path = breakPath(t)
+
+	if err := store.Put(1, UserState{Step: "budget"}); err == nil {
+		t.Fatal("Put: ожидалась ошибка записи")
+	}
+	if err := store.Put(2, UserState{Step: "budget"}); err == nil {
+		t.Fatal("Put: ожидалась ошибка записи")
+	}
+	if _, err := store.Modify(1, func(state *UserState) bool {
+		state.Step = "duration"
+		return true
+	}); err == nil {
+		t.Fatal("Modify: ожидалась ошибка записи")
+	}
+	if err := store.Delete(1); err == nil {
+		t.Fatal("Delete: ожидалась ошибка записи")
+	}
+
+	states, _ := store.List()
+	if len(states) != 1 || states[1].Step != "destination" {
+		t.Fatalf("после ошибок записи состояния = %+v, ожидалось прежнее состояние пользователя 1", states)
+	}
+}
+
+func TestFileSettingsStoreRollsBackOnWriteError(t *testing.T) {
+	store, err := NewFileSettingsStore(filepath.Join(t.TempDir(), "settings.json"))
+	if err != nil {
+		t.Fatalf("NewFileSettingsStore: %v", err)
+	}
+	if err := store.Put(1, UserSettings{Language: "ru"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	store.path = breakPath(t)
+
+	if err := store.Put(1, UserSettings{Language: "en"}); err == nil {
+		t.Fatal("Put: ожидалась ошибка записи")
+	}
+	if err := store.Put(2, UserSettings{Language: "en"}); err == nil {
+		t.Fatal("Put: ожидалась ошибка записи")
+	}
+
+	if got, _ := store.Get(1); got.Language != "ru" {
+		t.Errorf("язык пользователя 1 = %q, want ru", got.Language)
+	}
+	if got, _ := store.Get(2); got.Language != "" {
+		t.Errorf("у пользователя 2 остались несохраненные настройки %+v", got)
+	}
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TopicStore запоминает темы форума в чатах менеджеров, чтобы заявки
+// с одним ключом (например, направлением) попадали в одну тему.
+type TopicStore interface {
+	Get(chatID int64, key string) (int, bool, error)
+	Put(chatID int64, key string, threadID int) error
+	Delete(chatID int64, key string) error
+}
+
+// FileTopicStore держит темы в памяти и сохраняет их в JSON-файл
+// после каждого изменения.
+type FileTopicStore struct {
+	mu     sync.Mutex
+	path   string
+	topics map[string]int
+}
+
+func NewFileTopicStore(path string) (*FileTopicStore, error) {
+	store := &FileTopicStore{
+		path:   path,
+		topics: make(map[string]int),
+	}
+
+	if err := readJSONFile(path, &store.topics); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать темы из %s: %w", path, err)
+	}
+	if store.topics == nil {
+		store.topics = make(map[string]int)
+	}
+
+	return store, nil
+}
+
+func (s *FileTopicStore) Get(chatID int64, key string) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	threadID, exists := s.topics[topicKey(chatID, key)]
+	return threadID, exists, nil
+}
+
+func (s *FileTopicStore) Put(chatID int64, key string, threadID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.topics[topicKey(chatID, key)] = threadID
+	return writeJSONFile(s.path, s.topics)
+}
+
+func (s *FileTopicStore) Delete(chatID int64, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.topics[topicKey(chatID, key)]; !exists {
+		return nil
+	}
+
+	delete(s.topics, topicKey(chatID, key))
+	return writeJSONFile(s.path, s.topics)
+}
+
+func topicKey(chatID int64, key string) string {
+	return fmt.Sprintf("%d:%s", chatID, key)
+}
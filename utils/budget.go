@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"pumpkin_travel_tg_bot/models"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// budgetNumberRegexp — сумма с необязательным множителем. Группа из трех цифр
+	// после пробела, точки или запятой — разряд тысяч («80 000», «$3,000», «120.000»),
+	// иначе после точки или запятой идет дробная часть («1,5 млн»).
+	budgetNumberRegexp   = regexp.MustCompile(`(\d+(?:[ \x{00a0}.,]\d{3}\b)*)([.,]\d+)?\s*(тыс[а-я]*\.?|т\.?\s?р\.?|млн[а-я]*\.?|миллион[а-я]*|k\b|к(?:[^а-я]|$))?`)
+	budgetNextWordRegexp = regexp.MustCompile(`^[\s.]*([а-яa-z]+)`)
+
+	// budgetCountStems — слова после числа, которые означают не сумму, а число
+	// туристов или длительность: «на 2 человек», «за 7 ночей».
+	budgetCountStems = []string{"чел", "взросл", "ребен", "дет", "турист", "ноч", "ден", "дн", "недел",
+		"person", "people", "adult", "child", "kid", "pax", "night", "day", "week"}
+
+	budgetUpToWords = []string{"до ", "не более", "не больше", "максимум", "в пределах", "не дороже",
+		"up to", "under", "no more than", "max", "within"}
+	budgetFromWords = []string{"от ", "не менее", "не меньше", "минимум", "не дешевле",
+		"from ", "at least", "min"}
+)
+
+// isFlexibleBudget распознает ответы без конкретной суммы, например «Без строгих рамок».
+func isFlexibleBudget(text string) bool {
+	lower := strings.ToLower(text)
+	return strings.Contains(lower, "без строг") ||
+		strings.Contains(lower, "не имеет") ||
+		strings.Contains(lower, "не принципиал") ||
+		containsAny(lower, []string{"no strict", "no limit", "flexible", "doesn't matter", "does not matter"})
+}
+
+// ParseBudget разбирает бюджет вида «до 80 000 ₽», «200–250 тыс.», «от 3к $»
+// в диапазон сумм и валюту. Возвращает nil, если в ответе нет ни суммы,
+// ни признака гибкого бюджета.
+func ParseBudget(text string) *models.BudgetRange {
+	lower := strings.ToLower(text)
+	budget := &models.BudgetRange{
+		Currency: parseCurrency(lower),
+		Flexible: isFlexibleBudget(lower),
+	}
+
+	var amounts []int64
+	var multipliers []int64
+	for _, index := range budgetNumberRegexp.FindAllStringSubmatchIndex(lower, -1) {
+		if isCountNumber(lower[index[1]:]) {
+			continue
+		}
+
+		match := submatches(lower, index)
+		number := strings.NewReplacer(" ", "", " ", "", ".", "", ",", "").Replace(match[1]) +
+			strings.ReplaceAll(match[2], ",", ".")
+		value, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			continue
+		}
+
+		multiplier := budgetMultiplier(match[3])
+		amounts = append(amounts, int64(value*float64(multiplier)))
+		multipliers = append(multipliers, multiplier)
+	}
+
+	// В «200–250 тыс.» множитель относится к обоим числам.
+	if len(amounts) == 2 && multipliers[0] == 1 && multipliers[1] > 1 && amounts[0] < 1000 {
+		amounts[0] *= multipliers[1]
+	}
+
+	switch {
+	case len(amounts) >= 2:
+		budget.Min, budget.Max = amounts[0], amounts[1]
+		if budget.Min > budget.Max {
+			budget.Min, budget.Max = budget.Max, budget.Min
+		}
+	case len(amounts) == 1 && containsAny(lower, budgetUpToWords):
+		budget.Max = amounts[0]
+	case len(amounts) == 1 && containsAny(lower, budgetFromWords):
+		budget.Min = amounts[0]
+	case len(amounts) == 1:
+		budget.Min, budget.Max = amounts[0], amounts[0]
+	case !budget.Flexible:
+		return nil
+	}
+
+	return budget
+}
+
+// isCountNumber сообщает, что число перед rest — количество людей или дней, а не сумма.
+func isCountNumber(rest string) bool {
+	word := budgetNextWordRegexp.FindStringSubmatch(rest)
+	return word != nil && hasAnyPrefix(word[1], budgetCountStems)
+}
+
+// submatches возвращает группы совпадения по индексам; пропущенная группа — пустая строка.
+func submatches(text string, index []int) []string {
+	groups := make([]string, len(index)/2)
+	for i := range groups {
+		if index[2*i] >= 0 {
+			groups[i] = text[index[2*i]:index[2*i+1]]
+		}
+	}
+	return groups
+}
+
+func parseCurrency(lower string) string {
+	switch {
+	case strings.Contains(lower, "$") || strings.Contains(lower, "usd") || strings.Contains(lower, "долл"):
+		return models.CurrencyUSD
+	case strings.Contains(lower, "€") || strings.Contains(lower, "eur") || strings.Contains(lower, "евро"):
+		return models.CurrencyEUR
+	}
+	return models.CurrencyRUB
+}
+
+func budgetMultiplier(suffix string) int64 {
+	switch {
+	case suffix == "":
+		return 1
+	case strings.HasPrefix(suffix, "млн") || strings.HasPrefix(suffix, "миллион"):
+		return 1_000_000
+	}
+	return 1000
+}
+
+func containsAny(text string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(text, substr) {
+			return true
+		}
+	}
+	return false
+}
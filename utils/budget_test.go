@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"pumpkin_travel_tg_bot/models"
+	"reflect"
+	"testing"
+)
+
+func TestParseBudget(t *testing.T) {
+	tests := []struct {
+		text string
+		want *models.BudgetRange
+	}{
+		{"до 80 000 ₽", &models.BudgetRange{Max: 80_000, Currency: models.CurrencyRUB}},
+		{"200–250 тыс.", &models.BudgetRange{Min: 200_000, Max: 250_000, Currency: models.CurrencyRUB}},
+		{"от 100 до 150 тыс", &models.BudgetRange{Min: 100_000, Max: 150_000, Currency: models.CurrencyRUB}},
+		{"100 000 - 150 000 руб", &models.BudgetRange{Min: 100_000, Max: 150_000, Currency: models.CurrencyRUB}},
+		{"300 тысяч рублей", &models.BudgetRange{Min: 300_000, Max: 300_000, Currency: models.CurrencyRUB}},
+		{"до 200к", &models.BudgetRange{Max: 200_000, Currency: models.CurrencyRUB}},
+		{"1,5 млн", &models.BudgetRange{Min: 1_500_000, Max: 1_500_000, Currency: models.CurrencyRUB}},
+		{"150000", &models.BudgetRange{Min: 150_000, Max: 150_000, Currency: models.CurrencyRUB}},
+		{"от 3к $", &models.BudgetRange{Min: 3000, Currency: models.CurrencyUSD}},
+		{"2 500 €", &models.BudgetRange{Min: 2500, Max: 2500, Currency: models.CurrencyEUR}},
+		{"не более 5000 долларов", &models.BudgetRange{Max: 5000, Currency: models.CurrencyUSD}},
+		{"Без строгих рамок", &models.BudgetRange{Currency: models.CurrencyRUB, Flexible: true}},
+		{"up to $5000", &models.BudgetRange{Max: 5000, Currency: models.CurrencyUSD}},
+		{"from 2000 eur", &models.BudgetRange{Min: 2000, Currency: models.CurrencyEUR}},
+		{"100-150k", &models.BudgetRange{Min: 100_000, Max: 150_000, Currency: models.CurrencyRUB}},
+		{"no strict limits", &models.BudgetRange{Currency: models.CurrencyRUB, Flexible: true}},
+		{"150 тыс. на 2 человек", &models.BudgetRange{Min: 150_000, Max: 150_000, Currency: models.CurrencyRUB}},
+		{"до 300 тыс за 10 ночей", &models.BudgetRange{Max: 300_000, Currency: models.CurrencyRUB}},
+		{"120.000 руб", &models.BudgetRange{Min: 120_000, Max: 120_000, Currency: models.CurrencyRUB}},
+		{"2.5 млн", &models.BudgetRange{Min: 2_500_000, Max: 2_500_000, Currency: models.CurrencyRUB}},
+		{"$3,000", &models.BudgetRange{Min: 3000, Max: 3000, Currency: models.CurrencyUSD}},
+		{"$3,000 for 2 adults", &models.BudgetRange{Min: 3000, Max: 3000, Currency: models.CurrencyUSD}},
+		{"1,500,000 rub", &models.BudgetRange{Min: 1_500_000, Max: 1_500_000, Currency: models.CurrencyRUB}},
+		{"пока не знаю", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := ParseBudget(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseBudget(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
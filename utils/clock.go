@@ -0,0 +1,16 @@
+package utils
+
+import "time"
+
+// Clock возвращает текущее время. Планировщики получают его снаружи,
+// чтобы их расписание можно было проверить с подставными часами.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock — часы системы.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,406 @@
+package utils
+
+import (
+	"errors"
+	"pumpkin_travel_tg_bot/models"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	dateTokenRegexp = regexp.MustCompile(`[а-яa-z]+|\d{1,2}\.\d{1,2}(?:\.\d{2,4})?|\d+`)
+
+	monthStems = []struct {
+		stem  string
+		month time.Month
+	}{
+		{"январ", time.January}, {"феврал", time.February}, {"март", time.March},
+		{"апрел", time.April}, {"ма", time.May}, {"июн", time.June},
+		{"июл", time.July}, {"август", time.August}, {"сентябр", time.September},
+		{"октябр", time.October}, {"ноябр", time.November}, {"декабр", time.December},
+	}
+
+	// Английские месяцы сверяются целиком: по префиксам «dec» совпало бы
+	// с «decide», а «mar» — с «married».
+	englishMonths = map[string]time.Month{
+		"january": time.January, "jan": time.January,
+		"february": time.February, "feb": time.February,
+		"march": time.March, "mar": time.March,
+		"april": time.April, "apr": time.April,
+		"may":  time.May,
+		"june": time.June, "jun": time.June,
+		"july": time.July, "jul": time.July,
+		"august": time.August, "aug": time.August,
+		"september": time.September, "sep": time.September, "sept": time.September,
+		"october": time.October, "oct": time.October,
+		"november": time.November, "nov": time.November,
+		"december": time.December, "dec": time.December,
+	}
+
+	seasons = map[string][2]time.Month{
+		"зим":    {time.December, time.February},
+		"весн":   {time.March, time.May},
+		"лет":    {time.June, time.August},
+		"осен":   {time.September, time.November},
+		"winter": {time.December, time.February},
+		"spring": {time.March, time.May},
+		"summer": {time.June, time.August},
+		"autumn": {time.September, time.November},
+	}
+
+	// dateFillerWords не разрывают дату и диапазон дат: «10th of May»,
+	// «с 1 по 10 января», «от 10 до 20 мая», «May 10 to 20».
+	dateFillerWords = map[string]bool{
+		"st": true, "nd": true, "rd": true, "th": true, "of": true,
+		"с": true, "по": true, "от": true, "до": true, "и": true,
+		"from": true, "to": true, "till": true, "until": true, "and": true,
+	}
+)
+
+// ErrNonexistentDate — в ответе день, которого нет в месяце: «31 февраля».
+var ErrNonexistentDate = errors.New("такой даты не существует")
+
+type datePoint struct {
+	day   int
+	month time.Month
+	year  int
+}
+
+// ParseTravelDates разбирает даты поездки — «10–20 мая», «с 28 мая по 5 июня»,
+// «10.05–20.05», «Июнь», «конец июля», «летом», «на следующий месяц»,
+// «через 2 недели», «May 10–20», «in 2 weeks» — в окно дат. Год и относительные
+// выражения определяются относительно now. Возвращает nil, если дат в ответе нет,
+// и ErrNonexistentDate, если указан день, которого нет в месяце.
+func ParseTravelDates(text string, now time.Time) (*models.DateWindow, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	lower := strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	tokens := dateTokenRegexp.FindAllString(lower, -1)
+
+	if points := parseDatePoints(tokens); len(points) > 0 {
+		return pointsWindow(points, today)
+	}
+
+	if months := parseMonths(tokens); len(months) > 0 {
+		return monthsWindow(months, lower, today), nil
+	}
+
+	return parseRelativeDates(tokens, today), nil
+}
+
+// parseDatePoints находит конкретные дни: «10.05», «10 мая», «May 10», а также
+// дни перед месяцем в диапазоне «10–20 мая» и после него в «May 10–20».
+func parseDatePoints(tokens []string) []datePoint {
+	var points []datePoint
+	var pendingDays []int
+	consumed := 0
+
+	for i, token := range tokens {
+		if i < consumed || dateFillerWords[token] {
+			continue
+		}
+
+		if strings.Contains(token, ".") {
+			if point, ok := parseNumericDate(token); ok {
+				// Дни перед полной датой относятся к ее месяцу: «10-20.05.2027».
+				for _, day := range pendingDays {
+					points = append(points, datePoint{day: day, month: point.month, year: point.year})
+				}
+				points = append(points, point)
+			}
+			pendingDays = nil
+			continue
+		}
+
+		if day, err := strconv.Atoi(token); err == nil {
+			if day >= 1 && day <= 31 {
+				pendingDays = append(pendingDays, day)
+			} else if day >= 2000 && len(points) > 0 {
+				for j := range points {
+					if points[j].year == 0 {
+						points[j].year = day
+					}
+				}
+			}
+			continue
+		}
+
+		month, ok := parseMonth(token)
+		if ok && len(pendingDays) == 0 {
+			// Дни после месяца: «May 10–20», «June 5th, 2026».
+			var days []datePoint
+			consumed = i + 1
+			for ; consumed < len(tokens); consumed++ {
+				next := tokens[consumed]
+				if dateFillerWords[next] {
+					continue
+				}
+				n, err := strconv.Atoi(next)
+				if err != nil {
+					break
+				}
+				switch {
+				case n >= 1 && n <= 31:
+					days = append(days, datePoint{day: n, month: month})
+					continue
+				case n >= 2000:
+					for j := range days {
+						days[j].year = n
+					}
+					consumed++
+				}
+				break
+			}
+			points = append(points, days...)
+			continue
+		}
+		if !ok {
+			pendingDays = nil
+			continue
+		}
+
+		for _, day := range pendingDays {
+			points = append(points, datePoint{day: day, month: month})
+		}
+		pendingDays = nil
+
+		// Год после месяца: «10 мая 2026».
+		if i+1 < len(tokens) {
+			if year, err := strconv.Atoi(tokens[i+1]); err == nil && year >= 2000 {
+				for j := range points {
+					points[j].year = year
+				}
+			}
+		}
+	}
+
+	return points
+}
+
+func parseNumericDate(token string) (datePoint, bool) {
+	parts := strings.Split(token, ".")
+	day, _ := strconv.Atoi(parts[0])
+	month, _ := strconv.Atoi(parts[1])
+	if day < 1 || day > 31 || month < 1 || month > 12 {
+		return datePoint{}, false
+	}
+
+	point := datePoint{day: day, month: time.Month(month)}
+	if len(parts) == 3 {
+		year, _ := strconv.Atoi(parts[2])
+		if year < 100 {
+			year += 2000
+		}
+		point.year = year
+	}
+	return point, true
+}
+
+func parseMonth(token string) (time.Month, bool) {
+	if month, ok := englishMonths[token]; ok {
+		return month, true
+	}
+	// «ма» — слишком короткая основа, поэтому май проверяется по точным формам.
+	switch token {
+	case "май", "мая", "мае", "маю":
+		return time.May, true
+	}
+	for _, m := range monthStems {
+		if m.month != time.May && strings.HasPrefix(token, m.stem) {
+			return m.month, true
+		}
+	}
+	return 0, false
+}
+
+func parseMonths(tokens []string) []time.Month {
+	var months []time.Month
+	for _, token := range tokens {
+		if month, ok := parseMonth(token); ok {
+			months = append(months, month)
+		}
+	}
+	return months
+}
+
+func pointsWindow(points []datePoint, today time.Time) (*models.DateWindow, error) {
+	first, last := points[0], points[len(points)-1]
+
+	start := resolvePoint(first, today)
+	if !first.existsOn(start) {
+		return nil, ErrNonexistentDate
+	}
+	end := start
+	if len(points) > 1 {
+		last.year = firstNonZero(last.year, start.Year())
+		end = pointDate(last, today.Location())
+		// «с 28 декабря по 5 января» — окончание уже в следующем году.
+		if end.Before(start) {
+			last.year++
+			end = pointDate(last, today.Location())
+		}
+		if !last.existsOn(end) {
+			return nil, ErrNonexistentDate
+		}
+	}
+
+	return &models.DateWindow{Start: start, End: end}, nil
+}
+
+// existsOn сообщает, что дата date — тот самый день, а не перенос несуществующего
+// дня на следующий месяц: time.Date превращает «31 февраля» в 3 марта.
+func (p datePoint) existsOn(date time.Time) bool {
+	return date.Day() == p.day && date.Month() == p.month
+}
+
+// resolvePoint подставляет год: месяцы, которые в этом году уже прошли,
+// относятся к следующему году. Прошедшие дни текущего месяца остаются
+// в текущем году, чтобы их можно было отклонить как прошедшие.
+func resolvePoint(point datePoint, today time.Time) time.Time {
+	if point.year != 0 {
+		return pointDate(point, today.Location())
+	}
+
+	point.year = today.Year()
+	if point.month < today.Month() {
+		point.year++
+	}
+	return pointDate(point, today.Location())
+}
+
+func pointDate(point datePoint, loc *time.Location) time.Time {
+	return time.Date(point.year, point.month, point.day, 0, 0, 0, 0, loc)
+}
+
+// monthsWindow строит окно по месяцам: «Июнь», «июль-август», «конец мая».
+func monthsWindow(months []time.Month, lower string, today time.Time) *models.DateWindow {
+	startMonth, endMonth := months[0], months[len(months)-1]
+
+	year := today.Year()
+	if startMonth < today.Month() {
+		year++
+	}
+
+	start := time.Date(year, startMonth, 1, 0, 0, 0, 0, today.Location())
+	endYear := year
+	if endMonth < startMonth {
+		endYear++
+	}
+	end := lastDayOfMonth(endYear, endMonth, today.Location())
+
+	if len(months) == 1 {
+		switch {
+		case strings.Contains(lower, "начал") || containsAny(lower, []string{"early", "beginning", "start"}):
+			end = start.AddDate(0, 0, 9)
+		case strings.Contains(lower, "середин") || strings.Contains(lower, "mid"):
+			start = start.AddDate(0, 0, 10)
+			end = start.AddDate(0, 0, 9)
+		case strings.Contains(lower, "конц") || strings.Contains(lower, "конец") ||
+			containsAny(lower, []string{"end of", "late "}):
+			start = start.AddDate(0, 0, 20)
+		}
+	}
+
+	return clampToToday(&models.DateWindow{Start: start, End: end, Flexible: true}, today)
+}
+
+// parseRelativeDates разбирает выражения относительно сегодняшнего дня:
+// «следующий месяц», «в этом месяце», «через 2 недели», «летом», «как можно скорее»,
+// «next month», «in 2 weeks», «asap».
+func parseRelativeDates(tokens []string, today time.Time) *models.DateWindow {
+	joined := " " + strings.Join(tokens, " ") + " "
+	loc := today.Location()
+
+	switch {
+	case (strings.Contains(joined, "следующ") && strings.Contains(joined, "месяц")) ||
+		strings.Contains(joined, " next month "):
+		next := time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, loc)
+		return &models.DateWindow{Start: next, End: lastDayOfMonth(next.Year(), next.Month(), loc), Flexible: true}
+
+	case (strings.Contains(joined, " этот ") || strings.Contains(joined, " этом ")) && strings.Contains(joined, "месяц") ||
+		strings.Contains(joined, " this month "):
+		return &models.DateWindow{Start: today, End: lastDayOfMonth(today.Year(), today.Month(), loc), Flexible: true}
+
+	case strings.Contains(joined, "скорее") || strings.Contains(joined, "горящ") || strings.Contains(joined, " сейчас ") ||
+		containsAny(joined, []string{" asap ", " as soon as possible ", " last minute ", " now "}):
+		return &models.DateWindow{Start: today, End: today.AddDate(0, 0, 14), Flexible: true}
+	}
+
+	for i, token := range tokens {
+		if token != "через" && token != "in" {
+			continue
+		}
+
+		amount := 1
+		unitIndex := i + 1
+		if unitIndex < len(tokens) {
+			if n, err := strconv.Atoi(tokens[unitIndex]); err == nil {
+				amount = n
+				unitIndex++
+			}
+		}
+		if unitIndex >= len(tokens) {
+			break
+		}
+
+		var start time.Time
+		switch unit := tokens[unitIndex]; {
+		case strings.HasPrefix(unit, "ден") || strings.HasPrefix(unit, "дн") || strings.HasPrefix(unit, "day"):
+			start = today.AddDate(0, 0, amount)
+		case strings.HasPrefix(unit, "недел") || strings.HasPrefix(unit, "week"):
+			start = today.AddDate(0, 0, 7*amount)
+		case strings.HasPrefix(unit, "месяц") || strings.HasPrefix(unit, "month"):
+			start = today.AddDate(0, amount, 0)
+		default:
+			continue
+		}
+		return &models.DateWindow{Start: start, End: start.AddDate(0, 0, 7), Flexible: true}
+	}
+
+	for _, token := range tokens {
+		for stem, months := range seasons {
+			if !strings.HasPrefix(token, stem) {
+				continue
+			}
+			year := today.Year()
+			switch {
+			case months[0] > months[1] && today.Month() <= months[1]:
+				// Зима, которая уже идет: декабрь прошлого года.
+				year--
+			case months[0] <= months[1] && months[1] < today.Month():
+				year++
+			}
+			start := time.Date(year, months[0], 1, 0, 0, 0, 0, loc)
+			endYear := year
+			if months[1] < months[0] {
+				endYear++
+			}
+			window := &models.DateWindow{Start: start, End: lastDayOfMonth(endYear, months[1], loc), Flexible: true}
+			return clampToToday(window, today)
+		}
+	}
+
+	return nil
+}
+
+// clampToToday отрезает от гибкого окна уже прошедшие дни.
+func clampToToday(window *models.DateWindow, today time.Time) *models.DateWindow {
+	if window.Start.Before(today) && !window.End.Before(today) {
+		window.Start = today
+	}
+	return window
+}
+
+func lastDayOfMonth(year int, month time.Month, loc *time.Location) time.Time {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, loc)
+}
+
+func firstNonZero(values ...int) int {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"errors"
+	"pumpkin_travel_tg_bot/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTravelDates(t *testing.T) {
+	// Пятница, 15 августа: май и июнь в этом году уже прошли.
+	now := time.Date(2025, time.August, 15, 18, 30, 0, 0, time.UTC)
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	exact := func(start, end time.Time) *models.DateWindow {
+		return &models.DateWindow{Start: start, End: end}
+	}
+	flexible := func(start, end time.Time) *models.DateWindow {
+		return &models.DateWindow{Start: start, End: end, Flexible: true}
+	}
+
+	tests := []struct {
+		text string
+		want *models.DateWindow
+	}{
+		{"10–20 мая", exact(date(2026, time.May, 10), date(2026, time.May, 20))},
+		{"с 28 мая по 5 июня", exact(date(2026, time.May, 28), date(2026, time.June, 5))},
+		{"10.09–20.09", exact(date(2025, time.September, 10), date(2025, time.September, 20))},
+		{"с 28 декабря по 5 января", exact(date(2025, time.December, 28), date(2026, time.January, 5))},
+		{"с 1 по 10 января", exact(date(2026, time.January, 1), date(2026, time.January, 10))},
+		{"от 10 до 20 мая", exact(date(2026, time.May, 10), date(2026, time.May, 20))},
+		{"10-20.05.2027", exact(date(2027, time.May, 10), date(2027, time.May, 20))},
+		{"с 10 по 20.09", exact(date(2025, time.September, 10), date(2025, time.September, 20))},
+		{"from May 10 to 20", exact(date(2026, time.May, 10), date(2026, time.May, 20))},
+		{"10 мая 2027", exact(date(2027, time.May, 10), date(2027, time.May, 10))},
+		{"01.03.26", exact(date(2026, time.March, 1), date(2026, time.March, 1))},
+		{"5.08", exact(date(2025, time.August, 5), date(2025, time.August, 5))},
+		{"Июнь", flexible(date(2026, time.June, 1), date(2026, time.June, 30))},
+		{"сентябрь", flexible(date(2025, time.September, 1), date(2025, time.September, 30))},
+		{"август", flexible(date(2025, time.August, 15), date(2025, time.August, 31))},
+		{"конец октября", flexible(date(2025, time.October, 21), date(2025, time.October, 31))},
+		{"начало ноября", flexible(date(2025, time.November, 1), date(2025, time.November, 10))},
+		{"ноябрь-январь", flexible(date(2025, time.November, 1), date(2026, time.January, 31))},
+		{"летом", flexible(date(2025, time.August, 15), date(2025, time.August, 31))},
+		{"зимой", flexible(date(2025, time.December, 1), date(2026, time.February, 28))},
+		{"весной", flexible(date(2026, time.March, 1), date(2026, time.May, 31))},
+		{"на следующий месяц", flexible(date(2025, time.September, 1), date(2025, time.September, 30))},
+		{"через 2 недели", flexible(date(2025, time.August, 29), date(2025, time.September, 5))},
+		{"как можно скорее", flexible(date(2025, time.August, 15), date(2025, time.August, 29))},
+		{"May 10–20", exact(date(2026, time.May, 10), date(2026, time.May, 20))},
+		{"June 5th, 2026", exact(date(2026, time.June, 5), date(2026, time.June, 5))},
+		{"in 2 weeks", flexible(date(2025, time.August, 29), date(2025, time.September, 5))},
+		{"late September", flexible(date(2025, time.September, 21), date(2025, time.September, 30))},
+		{"decide later", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTravelDates(tt.text, now)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTravelDates(%q) = %+v, %v, want %+v", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseTravelDatesRejectsNonexistentDays(t *testing.T) {
+	now := time.Date(2025, time.August, 15, 18, 30, 0, 0, time.UTC)
+
+	for _, text := range []string{"31 февраля", "30.02", "с 25 по 31 ноября", "February 30", "29.02.2026"} {
+		if got, err := ParseTravelDates(text, now); !errors.Is(err, ErrNonexistentDate) {
+			t.Errorf("ParseTravelDates(%q) = %+v, %v, want ErrNonexistentDate", text, got, err)
+		}
+	}
+
+	// 29 февраля существует в високосном году.
+	if _, err := ParseTravelDates("29.02.2028", now); err != nil {
+		t.Errorf("ParseTravelDates(29.02.2028): %v", err)
+	}
+}
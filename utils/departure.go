@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"pumpkin_travel_tg_bot/catalog"
+	"pumpkin_travel_tg_bot/models"
+	"regexp"
+)
+
+// cityCodeRegexp — код города в скобках после названия: «Москва (MOW)».
+var cityCodeRegexp = regexp.MustCompile(`\s*\([A-Za-z]{3}\)\s*$`)
+
+// ParseDepartureCity находит город вылета в справочнике. Если в ответе
+// несколько городов, берется первый найденный. Возвращает nil, если
+// ни одного города из справочника в ответе нет.
+func ParseDepartureCity(text string) *models.DepartureAirport {
+	for _, part := range SplitDestinations(text) {
+		found, exact := catalog.FindCities(cityCodeRegexp.ReplaceAllString(part, ""))
+		if exact {
+			return &models.DepartureAirport{City: found[0].Name, IATA: found[0].IATA}
+		}
+	}
+	return nil
+}
+
+// SuggestDepartureCities предлагает города из справочника для ответа,
+// который не совпал ни с одним городом точно: по началу названия
+// или по похожему написанию.
+func SuggestDepartureCities(text string) []string {
+	parts := SplitDestinations(text)
+	if len(parts) != 1 {
+		return nil
+	}
+
+	found, exact := catalog.FindCities(cityCodeRegexp.ReplaceAllString(parts[0], ""))
+	if exact {
+		return nil
+	}
+
+	suggestions := make([]string, 0, len(found))
+	for _, city := range found {
+		suggestions = append(suggestions, city.String())
+	}
+	return suggestions
+}
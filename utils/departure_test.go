@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"pumpkin_travel_tg_bot/models"
+	"reflect"
+	"testing"
+)
+
+func TestParseDepartureCity(t *testing.T) {
+	moscow := &models.DepartureAirport{City: "Москва", IATA: "MOW"}
+	petersburg := &models.DepartureAirport{City: "Санкт-Петербург", IATA: "LED"}
+
+	tests := []struct {
+		text string
+		want *models.DepartureAirport
+	}{
+		{"Москва", moscow},
+		{"из Москвы", moscow},
+		{"Москва (MOW)", moscow},
+		{"MOW", moscow},
+		{"moscow", moscow},
+		{"Питер", petersburg},
+		{"санкт-петербург", petersburg},
+		{"спб или Москва", petersburg},
+		{"Казнь", nil},
+		{"Нью-Йорк", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := ParseDepartureCity(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseDepartureCity(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestDepartureCities(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Казнь", []string{"Казань (KZN)"}},
+		{"Екатеринбур", []string{"Екатеринбург (SVX)"}},
+		{"Москва", nil},
+		{"Москва / Казань", nil},
+		{"Нью-Йорк", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := SuggestDepartureCities(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SuggestDepartureCities(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"pumpkin_travel_tg_bot/catalog"
+	"regexp"
+	"strings"
+)
+
+var (
+	// destinationSeparatorRegexp делит ответ на направления по знакам-разделителям
+	// и по отдельным словам «и», «или», «либо» — но не внутри слов вроде «Индия».
+	destinationSeparatorRegexp = regexp.MustCompile(`(?i)\s*[/,;+|\n]\s*|\s+(?:и|или|либо)\s+`)
+	leadingConjunctionRegexp   = regexp.MustCompile(`(?i)^(?:и|или|либо)\s+`)
+
+	undecidedDestinationWords = []string{
+		"не определ", "не знаю", "не решил", "куда угодно", "без разницы",
+		"все равно", "всё равно", "любая", "любое", "подберите", "посоветуйте", "на ваш выбор",
+	}
+)
+
+// SplitDestinations делит ответ о направлении на отдельные направления.
+func SplitDestinations(text string) []string {
+	var parts []string
+	for _, part := range destinationSeparatorRegexp.Split(strings.TrimSpace(text), -1) {
+		part = leadingConjunctionRegexp.ReplaceAllString(strings.TrimSpace(part), "")
+		part = strings.Trim(part, " .!?")
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// IsUndecidedDestination распознает ответы вроде «Пока не определились».
+func IsUndecidedDestination(text string) bool {
+	lower := strings.ToLower(text)
+	for _, word := range undecidedDestinationWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDestinations разбирает ответ о направлении в список канонических
+// названий из каталога. Направления не из каталога остаются как написаны.
+// undecided — клиент еще не выбрал направление или готов рассмотреть другие.
+func ParseDestinations(text string) (destinations []string, undecided bool) {
+	seen := make(map[string]bool)
+	for _, part := range SplitDestinations(text) {
+		if IsUndecidedDestination(part) {
+			undecided = true
+			continue
+		}
+
+		name := part
+		if destination, exact, ok := catalog.FindDestination(part); ok && exact {
+			name = destination.String()
+		}
+		if !seen[name] {
+			seen[name] = true
+			destinations = append(destinations, name)
+		}
+	}
+	return destinations, undecided
+}
+
+// SuggestDestinations исправляет в ответе названия, похожие на направления
+// из каталога. Возвращает исправленный ответ и true, если было что исправить.
+func SuggestDestinations(text string) (string, bool) {
+	parts := SplitDestinations(text)
+	corrected := false
+	for i, part := range parts {
+		if IsUndecidedDestination(part) {
+			continue
+		}
+		if destination, exact, ok := catalog.FindDestination(part); ok && !exact {
+			parts[i] = destination.Name
+			corrected = true
+		}
+	}
+	if !corrected {
+		return text, false
+	}
+	return strings.Join(parts, " / "), true
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitDestinations(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Турция", []string{"Турция"}},
+		{"Турция / Египет", []string{"Турция", "Египет"}},
+		{"Турция, Египет или ОАЭ", []string{"Турция", "Египет", "ОАЭ"}},
+		{"Турция и Египет", []string{"Турция", "Египет"}},
+		{"Индия", []string{"Индия"}},
+		{"Турция;\nи Кипр.", []string{"Турция", "Кипр"}},
+		{"  ", nil},
+	}
+
+	for _, tt := range tests {
+		if got := SplitDestinations(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitDestinations(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseDestinations(t *testing.T) {
+	tests := []struct {
+		text          string
+		want          []string
+		wantUndecided bool
+	}{
+		{"Турция", []string{"Турция"}, false},
+		{"в Турцию", []string{"Турция"}, false},
+		{"turkey", []string{"Турция"}, false},
+		{"Анталья", []string{"Анталья (Турция)"}, false},
+		{"Эмираты / Тай", []string{"ОАЭ", "Таиланд"}, false},
+		{"Турция, турцию", []string{"Турция"}, false},
+		{"Турция или посоветуйте", []string{"Турция"}, true},
+		{"Пока не определились", nil, true},
+		{"Атлантида", []string{"Атлантида"}, false},
+		{"", nil, false},
+	}
+
+	for _, tt := range tests {
+		got, undecided := ParseDestinations(tt.text)
+		if !reflect.DeepEqual(got, tt.want) || undecided != tt.wantUndecided {
+			t.Errorf("ParseDestinations(%q) = %q, %v, want %q, %v", tt.text, got, undecided, tt.want, tt.wantUndecided)
+		}
+	}
+}
+
+func TestSuggestDestinations(t *testing.T) {
+	tests := []struct {
+		text      string
+		want      string
+		corrected bool
+	}{
+		{"Егпиет", "Египет", true},
+		{"Турция / Егпиет", "Турция / Египет", true},
+		{"Турция", "Турция", false},
+		{"не знаю", "не знаю", false},
+	}
+
+	for _, tt := range tests {
+		got, corrected := SuggestDestinations(tt.text)
+		if got != tt.want || corrected != tt.corrected {
+			t.Errorf("SuggestDestinations(%q) = %q, %v, want %q, %v", tt.text, got, corrected, tt.want, tt.corrected)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"pumpkin_travel_tg_bot/models"
+	"regexp"
+	"strings"
+)
+
+var (
+	durationTokenRegexp = regexp.MustCompile(`[а-яa-z]+|\d+`)
+
+	durationUpToWords = []string{"до ", "не более", "не больше", "не дольше", "максимум",
+		"up to", "no more than", "at most", "max"}
+	durationFromWords = []string{"от ", "не менее", "не меньше", "минимум",
+		"from ", "at least", "min"}
+)
+
+// ParseDuration разбирает длительность отдыха — «неделя», «10–14 дней»,
+// «7 ночей», «2 недели», «выходные», «10 nights», «a week» — в диапазон ночей. Дни переводятся
+// в ночи как «дней минус один», недели — по 7 ночей. У «от 7 ночей» верхняя
+// граница не указана. Возвращает nil, если длительность определить не удалось.
+func ParseDuration(text string) *models.NightsRange {
+	lower := strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	tokens := durationTokenRegexp.FindAllString(lower, -1)
+
+	var values []int
+	var pending []int
+
+	for _, token := range tokens {
+		if n, ok := tokenNumber(token); ok {
+			pending = append(pending, n)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(token, "выходн") || strings.HasPrefix(token, "weekend"):
+			values = append(values, 2)
+		case strings.HasPrefix(token, "fortnight"):
+			values = append(values, scaleDuration(pending, 14, 0)...)
+		case strings.HasPrefix(token, "недел") || strings.HasPrefix(token, "week"):
+			values = append(values, scaleDuration(pending, 7, 0)...)
+		case strings.HasPrefix(token, "месяц") || strings.HasPrefix(token, "month"):
+			values = append(values, scaleDuration(pending, 30, 0)...)
+		case strings.HasPrefix(token, "ноч") || strings.HasPrefix(token, "night"):
+			values = append(values, scaleDuration(pending, 1, 0)...)
+		case strings.HasPrefix(token, "ден") || strings.HasPrefix(token, "дн") || strings.HasPrefix(token, "сут") ||
+			strings.HasPrefix(token, "day"):
+			values = append(values, scaleDuration(pending, 1, 1)...)
+		default:
+			continue
+		}
+		pending = nil
+	}
+
+	// Числа без единиц — это ответ на вопрос «Сколько дней?».
+	if len(values) == 0 && len(pending) > 0 {
+		values = scaleDuration(pending, 1, 1)
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	nights := &models.NightsRange{Min: values[0], Max: values[0]}
+	for _, v := range values[1:] {
+		if v < nights.Min {
+			nights.Min = v
+		}
+		if v > nights.Max {
+			nights.Max = v
+		}
+	}
+
+	// «до» и «от» меняют границу только у одного числа: в «от 7 до 10 ночей» обе границы уже есть.
+	switch {
+	case containsAny(lower, durationUpToWords) && len(values) == 1:
+		nights.Min = 1
+	case containsAny(lower, durationFromWords) && len(values) == 1:
+		nights.Max = 0
+	}
+
+	return nights
+}
+
+// scaleDuration переводит числа перед единицей измерения в ночи.
+// Единица без числа («неделя») считается за одну.
+func scaleDuration(numbers []int, multiplier, subtract int) []int {
+	if len(numbers) == 0 {
+		numbers = []int{1}
+	}
+
+	result := make([]int, 0, len(numbers))
+	for _, n := range numbers {
+		nights := n*multiplier - subtract
+		if nights < 1 {
+			nights = 1
+		}
+		result = append(result, nights)
+	}
+	return result
+}
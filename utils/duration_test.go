@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"pumpkin_travel_tg_bot/models"
+	"reflect"
+	"testing"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		text string
+		want *models.NightsRange
+	}{
+		{"7 ночей", &models.NightsRange{Min: 7, Max: 7}},
+		{"10–14 дней", &models.NightsRange{Min: 9, Max: 13}},
+		{"10-14 ночей", &models.NightsRange{Min: 10, Max: 14}},
+		{"от 7 до 10 ночей", &models.NightsRange{Min: 7, Max: 10}},
+		{"от 7 до 10 дней", &models.NightsRange{Min: 6, Max: 9}},
+		{"до 10 ночей", &models.NightsRange{Min: 1, Max: 10}},
+		{"не больше недели", &models.NightsRange{Min: 1, Max: 7}},
+		{"от 7 ночей", &models.NightsRange{Min: 7}},
+		{"минимум неделя", &models.NightsRange{Min: 7}},
+		{"не дольше 5 дней", &models.NightsRange{Min: 1, Max: 4}},
+		{"неделя", &models.NightsRange{Min: 7, Max: 7}},
+		{"2 недели", &models.NightsRange{Min: 14, Max: 14}},
+		{"1-2 недели", &models.NightsRange{Min: 7, Max: 14}},
+		{"выходные", &models.NightsRange{Min: 2, Max: 2}},
+		{"месяц", &models.NightsRange{Min: 30, Max: 30}},
+		{"10", &models.NightsRange{Min: 9, Max: 9}},
+		{"from 7 to 10 nights", &models.NightsRange{Min: 7, Max: 10}},
+		{"up to 10 nights", &models.NightsRange{Min: 1, Max: 10}},
+		{"at least 10 nights", &models.NightsRange{Min: 10}},
+		{"a week", &models.NightsRange{Min: 7, Max: 7}},
+		{"weekend", &models.NightsRange{Min: 2, Max: 2}},
+		{"сколько получится", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := ParseDuration(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseDuration(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"pumpkin_travel_tg_bot/models"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	travelersTokenRegexp = regexp.MustCompile(`[а-яa-z]+|\d+|\+`)
+	ageNumberRegexp      = regexp.MustCompile(`\d+`)
+
+	numberWords = map[string]int{
+		"один": 1, "одна": 1, "одного": 1, "одним": 1, "одной": 1,
+		"два": 2, "две": 2, "двое": 2, "двух": 2, "двумя": 2,
+		"три": 3, "трое": 3, "трех": 3, "тремя": 3,
+		"четыре": 4, "четверо": 4, "четырех": 4, "четырьмя": 4,
+		"пять": 5, "пятеро": 5, "пятью": 5,
+		"шесть": 6, "шестеро": 6,
+		"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	}
+
+	togetherWords = map[string]int{
+		"один": 1, "одна": 1, "вдвоем": 2, "втроем": 3, "вчетвером": 4, "впятером": 5,
+		"alone": 1, "solo": 1,
+	}
+
+	adultStems = []string{"взросл", "чел", "муж", "жен", "супруг", "пар",
+		"adult", "grown", "person", "people", "pax", "husband", "wife", "couple"}
+	childStems = []string{"ребен", "дет", "малыш", "сын", "доч", "ребят",
+		"child", "kid", "infant", "baby", "toddler", "son", "daughter"}
+)
+
+// ParseTravelers разбирает состав туристов: «2 взрослых + 1 ребёнок»,
+// «двое взрослых и двое детей», «2+1», «вдвоем с ребенком», «2 adults and a kid».
+// Возвращает nil, если количество путешественников определить не удалось.
+func ParseTravelers(text string) *models.TravelParty {
+	lower := strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	tokens := travelersTokenRegexp.FindAllString(lower, -1)
+
+	// «2+1» — взрослые плюс дети.
+	if len(tokens) == 3 && tokens[1] == "+" {
+		adults, errA := strconv.Atoi(tokens[0])
+		children, errC := strconv.Atoi(tokens[2])
+		if errA == nil && errC == nil {
+			return &models.TravelParty{Adults: adults, Children: children}
+		}
+	}
+
+	party := &models.TravelParty{}
+	found := false
+	lastNumber := 0
+
+	for _, token := range tokens {
+		if n, ok := tokenNumber(token); ok {
+			lastNumber = n
+			continue
+		}
+
+		count := lastNumber
+		lastNumber = 0
+
+		switch {
+		case hasAnyPrefix(token, childStems):
+			if count == 0 {
+				count = 1
+			}
+			party.Children += count
+			found = true
+		case hasAnyPrefix(token, adultStems):
+			if count == 0 {
+				// «пара», «с мужем» без числа.
+				count = adultsWithoutNumber(token)
+			}
+			party.Adults += count
+			found = true
+		case token == "я" || token == "i" || token == "me":
+			party.Adults++
+			found = true
+		default:
+			if n, ok := togetherWords[token]; ok && party.Adults == 0 {
+				party.Adults = n
+				found = true
+			}
+		}
+	}
+
+	// Одно число без пояснений — количество взрослых.
+	if !found && lastNumber > 0 && len(tokens) == 1 {
+		return &models.TravelParty{Adults: lastNumber}
+	}
+
+	if !found {
+		return nil
+	}
+	return party
+}
+
+// ParseChildAge разбирает возраст ребенка в годах: «5», «3 года», «8 месяцев», «до года».
+func ParseChildAge(text string) (int, bool) {
+	lower := strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+
+	if strings.Contains(lower, "мес") || strings.Contains(lower, "до год") ||
+		strings.Contains(lower, "младен") || strings.Contains(lower, "грудн") ||
+		strings.Contains(lower, "month") || strings.Contains(lower, "infant") || strings.Contains(lower, "baby") {
+		return 0, true
+	}
+
+	if number := ageNumberRegexp.FindString(lower); number != "" {
+		age, err := strconv.Atoi(number)
+		return age, err == nil
+	}
+
+	for _, token := range travelersTokenRegexp.FindAllString(lower, -1) {
+		if n, ok := numberWords[token]; ok {
+			return n, true
+		}
+	}
+
+	return 0, false
+}
+
+func tokenNumber(token string) (int, bool) {
+	if n, err := strconv.Atoi(token); err == nil {
+		return n, true
+	}
+	n, ok := numberWords[token]
+	return n, ok
+}
+
+func adultsWithoutNumber(token string) int {
+	if strings.HasPrefix(token, "пар") || strings.HasPrefix(token, "couple") {
+		return 2
+	}
+	return 1
+}
+
+func hasAnyPrefix(token string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(token, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"pumpkin_travel_tg_bot/models"
+	"reflect"
+	"testing"
+)
+
+func TestParseTravelers(t *testing.T) {
+	tests := []struct {
+		text string
+		want *models.TravelParty
+	}{
+		{"2 взрослых + 1 ребёнок", &models.TravelParty{Adults: 2, Children: 1}},
+		{"2+1", &models.TravelParty{Adults: 2, Children: 1}},
+		{"2 + 2", &models.TravelParty{Adults: 2, Children: 2}},
+		{"двое взрослых и двое детей", &models.TravelParty{Adults: 2, Children: 2}},
+		{"вдвоем с ребенком", &models.TravelParty{Adults: 2, Children: 1}},
+		{"втроем", &models.TravelParty{Adults: 3}},
+		{"1 взрослый", &models.TravelParty{Adults: 1}},
+		{"3 человека", &models.TravelParty{Adults: 3}},
+		{"я с мужем", &models.TravelParty{Adults: 2}},
+		{"семейная пара", &models.TravelParty{Adults: 2}},
+		{"2 взрослых + ребенок 5 лет", &models.TravelParty{Adults: 2, Children: 1}},
+		{"3", &models.TravelParty{Adults: 3}},
+		{"2 adults and a kid", &models.TravelParty{Adults: 2, Children: 1}},
+		{"two adults, two kids", &models.TravelParty{Adults: 2, Children: 2}},
+		{"solo", &models.TravelParty{Adults: 1}},
+		{"не знаю", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := ParseTravelers(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTravelers(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseChildAge(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   int
+		wantOK bool
+	}{
+		{"5", 5, true},
+		{"3 года", 3, true},
+		{"12 лет", 12, true},
+		{"8 месяцев", 0, true},
+		{"до года", 0, true},
+		{"грудной", 0, true},
+		{"пять", 5, true},
+		{"7 years", 7, true},
+		{"baby", 0, true},
+		{"не знаю", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseChildAge(tt.text)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseChildAge(%q) = %d, %v, want %d, %v", tt.text, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -11,30 +11,18 @@ func ValidateNotEmpty(text string) bool {
 
 func ValidateBudget(text string) bool {
 	hasDigits := regexp.MustCompile(`\d`).MatchString(text)
-	hasFlexible := strings.Contains(strings.ToLower(text), "без строг") ||
-		strings.Contains(strings.ToLower(text), "не имеет") ||
-		strings.Contains(strings.ToLower(text), "не принципиал")
 
-	return (hasDigits || hasFlexible) && ValidateNotEmpty(text)
+	return (hasDigits || isFlexibleBudget(text)) && ValidateNotEmpty(text)
 }
 
+// ValidateCountries возвращает направления из ответа без «Пока не определились».
 func ValidateCountries(countriesStr string) []string {
-	separators := []string{"/", ",", "и", "или"}
-
-	result := countriesStr
-	for _, sep := range separators {
-		result = strings.ReplaceAll(result, sep, ",")
-	}
-
-	countries := strings.Split(result, ",")
-	var cleanCountries []string
-
-	for _, country := range countries {
-		trimmed := strings.TrimSpace(country)
-		if trimmed != "" && trimmed != "пока не определились" {
-			cleanCountries = append(cleanCountries, trimmed)
+	var countries []string
+	for _, country := range SplitDestinations(countriesStr) {
+		if !IsUndecidedDestination(country) {
+			countries = append(countries, country)
 		}
 	}
 
-	return cleanCountries
+	return countries
 }